@@ -2,7 +2,9 @@ package ast
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/mahiro72/monkey-lang/token"
 )
@@ -117,6 +119,58 @@ func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
 
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
+
+type StringLiteral struct {
+	Token token.Token
+	Value string // エスケープシーケンス展開後の値
+}
+
+func (sl *StringLiteral) expressionNode()      {}
+func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) String() string       { return quote(sl.Value) }
+
+// 文字列をエスケープしてダブルクォートで囲む
+func quote(s string) string {
+	var out strings.Builder
+
+	out.WriteByte('"')
+	for i, r := range s {
+		switch r {
+		case '"':
+			out.WriteString(`\"`)
+		case '\\':
+			out.WriteString(`\\`)
+		case '\n':
+			out.WriteString(`\n`)
+		case '\t':
+			out.WriteString(`\t`)
+		case utf8.RuneError:
+			// 不正なUTF-8のバイトは字句解析器と同じくそのまま残す
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+				out.WriteByte(s[i])
+			} else {
+				out.WriteRune(r)
+			}
+		default:
+			if r < ' ' || r == 0x7f {
+				fmt.Fprintf(&out, `\u{%x}`, r)
+			} else {
+				out.WriteRune(r)
+			}
+		}
+	}
+	out.WriteByte('"')
+	return out.String()
+}
+
 type PrefixExpression struct {
 	Token    token.Token //前置トークン !など
 	Operator string
@@ -177,18 +231,44 @@ func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
 
-	out.WriteString("if")
+	// 条件と分岐の境目が分かるよう、分岐は波括弧で囲む
+	out.WriteString("if ")
 	out.WriteString(ie.Condition.String())
 	out.WriteString(" ")
-	out.WriteString(ie.Consequence.String())
+	out.WriteString(bracedBlockString(ie.Consequence))
 
 	if ie.Alternative != nil {
 		out.WriteString(" else ")
-		out.WriteString(ie.Alternative.String())
+		if elseIf := ie.elseIf(); elseIf != nil {
+			out.WriteString(elseIf.String())
+		} else {
+			out.WriteString(bracedBlockString(ie.Alternative))
+		}
 	}
 	return out.String()
 }
 
+// else ifの場合は続くif式を返す。構文解析器はelse ifを、ifトークンを持ちif式だけを含むブロックにする
+func (ie *IfExpression) elseIf() *IfExpression {
+	alt := ie.Alternative
+	if alt.Token.Type != token.IF || len(alt.Statements) != 1 {
+		return nil
+	}
+	stmt, ok := alt.Statements[0].(*ExpressionStatement)
+	if !ok {
+		return nil
+	}
+	elseIf, _ := stmt.Expression.(*IfExpression)
+	return elseIf
+}
+
+func bracedBlockString(bs *BlockStatement) string {
+	if len(bs.Statements) == 0 {
+		return "{}"
+	}
+	return "{ " + bs.String() + " }"
+}
+
 type BlockStatement struct {
 	Token      token.Token
 	Statements []Statement
@@ -209,6 +289,7 @@ type FunctionLiteral struct {
 	Token      token.Token // fnトークン
 	Parameters []*Identifier
 	Body       *BlockStatement
+	Name       string // let文で束縛された場合の名前 (無名関数の場合は空文字)
 }
 
 func (fl *FunctionLiteral) expressionNode()      {}
@@ -229,6 +310,32 @@ func (fl *FunctionLiteral) String() string {
 	return out.String()
 }
 
+// macro(<parameters>) { <body> }
+// マクロ展開の段階でのみ使われ、評価の対象にはならない
+type MacroLiteral struct {
+	Token      token.Token // macroトークン
+	Parameters []*Identifier
+	Body       *BlockStatement
+}
+
+func (ml *MacroLiteral) expressionNode()      {}
+func (ml *MacroLiteral) TokenLiteral() string { return ml.Token.Literal }
+func (ml *MacroLiteral) String() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range ml.Parameters {
+		params = append(params, p.String())
+	}
+	out.WriteString(ml.TokenLiteral())
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") ")
+	out.WriteString(ml.Body.String())
+
+	return out.String()
+}
+
 type CallExpression struct {
 	Token     token.Token // '('トークン
 	Function  Expression  // Identifier or FunctionLiteral
@@ -252,3 +359,213 @@ func (ce *CallExpression) String() string {
 
 	return out.String()
 }
+
+type ArrayLiteral struct {
+	Token    token.Token // '['トークン
+	Elements []Expression
+}
+
+func (al *ArrayLiteral) expressionNode()      {}
+func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) String() string {
+	var out bytes.Buffer
+
+	elements := []string{}
+	for _, el := range al.Elements {
+		elements = append(elements, el.String())
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+// <left>[<index>]
+type IndexExpression struct {
+	Token token.Token // '['トークン
+	Left  Expression
+	Index Expression
+}
+
+func (ie *IndexExpression) expressionNode()      {}
+func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ie.Left.String())
+	out.WriteString("[")
+	out.WriteString(ie.Index.String())
+	out.WriteString("])")
+
+	return out.String()
+}
+
+// {<key>: <value>, ...}
+type HashLiteral struct {
+	Token token.Token // '{'トークン
+	Pairs []*HashPair // ソース上の記述順
+}
+
+type HashPair struct {
+	Key   Expression
+	Value Expression
+}
+
+func (hl *HashLiteral) expressionNode()      {}
+func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) String() string {
+	var out bytes.Buffer
+
+	pairs := []string{}
+	for _, pair := range hl.Pairs {
+		pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// <target> = <value> や <target> += <value> などの代入式
+// Targetは識別子または添字アクセス
+type AssignExpression struct {
+	Token    token.Token // 代入演算子のトークン
+	Target   Expression
+	Operator string
+	Value    Expression
+}
+
+func (ae *AssignExpression) expressionNode()      {}
+func (ae *AssignExpression) TokenLiteral() string { return ae.Token.Literal }
+func (ae *AssignExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ae.Target.String())
+	out.WriteString(" " + ae.Operator + " ")
+	out.WriteString(ae.Value.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// <condition> ? <consequence> : <alternative>
+type ConditionalExpression struct {
+	Token       token.Token // '?'トークン
+	Condition   Expression
+	Consequence Expression
+	Alternative Expression
+}
+
+func (ce *ConditionalExpression) expressionNode()      {}
+func (ce *ConditionalExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *ConditionalExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ce.Condition.String())
+	out.WriteString(" ? ")
+	out.WriteString(ce.Consequence.String())
+	out.WriteString(" : ")
+	out.WriteString(ce.Alternative.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// while (<condition>) { <body> }
+type WhileStatement struct {
+	Token     token.Token // whileトークン
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode()       {}
+func (ws *WhileStatement) TokenLiteral() string { return ws.Token.Literal }
+func (ws *WhileStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("while ")
+	out.WriteString(ws.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(ws.Body.String())
+
+	return out.String()
+}
+
+// for (<init>; <condition>; <post>) { <body> }
+// Init, Condition, Postはいずれも省略でき、その場合はnil
+type ForStatement struct {
+	Token     token.Token // forトークン
+	Init      Statement   // let文または式文
+	Condition Expression
+	Post      Statement // let文または式文
+	Body      *BlockStatement
+}
+
+func (fs *ForStatement) statementNode()       {}
+func (fs *ForStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+	if fs.Init != nil {
+		out.WriteString(strings.TrimSuffix(fs.Init.String(), ";"))
+	}
+	out.WriteString("; ")
+	if fs.Condition != nil {
+		out.WriteString(fs.Condition.String())
+	}
+	out.WriteString("; ")
+	if fs.Post != nil {
+		out.WriteString(strings.TrimSuffix(fs.Post.String(), ";"))
+	}
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+
+	return out.String()
+}
+
+// for (<variable> in <iterable>) { <body> }
+type ForInStatement struct {
+	Token    token.Token // forトークン
+	Variable *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fs *ForInStatement) statementNode()       {}
+func (fs *ForInStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForInStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+	out.WriteString(fs.Variable.String())
+	out.WriteString(" in ")
+	out.WriteString(fs.Iterable.String())
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+
+	return out.String()
+}
+
+type BreakStatement struct {
+	Token token.Token // breakトークン
+}
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) String() string       { return bs.Token.Literal + ";" }
+
+type ContinueStatement struct {
+	Token token.Token // continueトークン
+}
+
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) String() string       { return cs.Token.Literal + ";" }
@@ -0,0 +1,564 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/mahiro72/monkey-lang/token"
+)
+
+// ノードをJSONに変換する。各ノードは"type"にノードの型名 ("InfixExpression"など) を持つオブジェクトになり、
+// 続けてトークン (位置を含む) とフィールドを小文字始まりの名前で持つ。nilの子はnullになる
+// 例: {"type": "Identifier", "token": {"type": "IDENT", "literal": "x", "pos": {...}, "end": {...}}, "value": "x"}
+// IntegerLiteralの"value"は、2^53を超える値を丸めるJavaScriptなどでも正確に読めるよう10進数の文字列にする
+// JSONの文字列はUTF-8なので、文字列リテラルに含まれる不正なUTF-8のバイトはU+FFFDに置き換わる
+func Marshal(node Node) ([]byte, error) {
+	var out bytes.Buffer
+	if err := writeJSON(&out, encodeNode(node)); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// Marshalで変換したJSONからノードを組み立てる
+func Unmarshal(data []byte) (Node, error) {
+	d := &decoder{}
+	node := d.node(data)
+	if d.err != nil {
+		return nil, d.err
+	}
+	return node, nil
+}
+
+// フィールドを追加した順に出力するJSONオブジェクト ("type"を先頭にするため)
+type jsonObject []jsonField
+
+type jsonField struct {
+	key   string
+	value any
+}
+
+// オブジェクトと配列は順に書き出し、それ以外の値 (トークンや文字列など) はencoding/jsonで変換する
+// ソースコードの"<"や"&"が読みにくくならないように、\u003cなどへのエスケープはしない
+func writeJSON(out *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case jsonObject:
+		out.WriteByte('{')
+		for i, field := range v {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			if err := writeJSON(out, field.key); err != nil {
+				return err
+			}
+			out.WriteByte(':')
+			if err := writeJSON(out, field.value); err != nil {
+				return err
+			}
+		}
+		out.WriteByte('}')
+	case []any:
+		if v == nil {
+			out.WriteString("null")
+			return nil
+		}
+		out.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			if err := writeJSON(out, item); err != nil {
+				return err
+			}
+		}
+		out.WriteByte(']')
+	default:
+		enc := json.NewEncoder(out)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		out.Truncate(out.Len() - 1) // Encodeが付ける改行を取り除く
+	}
+	return nil
+}
+
+func nodeObject(typ string, tok token.Token, fields ...jsonField) jsonObject {
+	return append(jsonObject{{"type", typ}, {"token", tok}}, fields...)
+}
+
+func encodeNode(node Node) any {
+	switch n := node.(type) {
+	// 文
+	case *Program:
+		return jsonObject{{"type", "Program"}, {"statements", encodeStatements(n.Statements)}}
+	case *LetStatement:
+		return nodeObject("LetStatement", n.Token,
+			jsonField{"name", encodeIdentifier(n.Name)},
+			jsonField{"value", encodeNode(n.Value)})
+	case *ReturnStatement:
+		return nodeObject("ReturnStatement", n.Token,
+			jsonField{"returnValue", encodeNode(n.ReturnValue)})
+	case *ExpressionStatement:
+		return nodeObject("ExpressionStatement", n.Token,
+			jsonField{"expression", encodeNode(n.Expression)})
+	case *BlockStatement:
+		return nodeObject("BlockStatement", n.Token,
+			jsonField{"statements", encodeStatements(n.Statements)})
+	case *WhileStatement:
+		return nodeObject("WhileStatement", n.Token,
+			jsonField{"condition", encodeNode(n.Condition)},
+			jsonField{"body", encodeBlock(n.Body)})
+	case *ForStatement:
+		return nodeObject("ForStatement", n.Token,
+			jsonField{"init", encodeNode(n.Init)},
+			jsonField{"condition", encodeNode(n.Condition)},
+			jsonField{"post", encodeNode(n.Post)},
+			jsonField{"body", encodeBlock(n.Body)})
+	case *ForInStatement:
+		return nodeObject("ForInStatement", n.Token,
+			jsonField{"variable", encodeIdentifier(n.Variable)},
+			jsonField{"iterable", encodeNode(n.Iterable)},
+			jsonField{"body", encodeBlock(n.Body)})
+	case *BreakStatement:
+		return nodeObject("BreakStatement", n.Token)
+	case *ContinueStatement:
+		return nodeObject("ContinueStatement", n.Token)
+
+	// 式
+	case *Identifier:
+		return nodeObject("Identifier", n.Token, jsonField{"value", n.Value})
+	case *IntegerLiteral:
+		return nodeObject("IntegerLiteral", n.Token, jsonField{"value", strconv.FormatInt(n.Value, 10)})
+	case *FloatLiteral:
+		return nodeObject("FloatLiteral", n.Token, jsonField{"value", n.Value})
+	case *StringLiteral:
+		return nodeObject("StringLiteral", n.Token, jsonField{"value", n.Value})
+	case *Boolean:
+		return nodeObject("Boolean", n.Token, jsonField{"value", n.Value})
+	case *PrefixExpression:
+		return nodeObject("PrefixExpression", n.Token,
+			jsonField{"operator", n.Operator},
+			jsonField{"right", encodeNode(n.Right)})
+	case *InfixExpression:
+		return nodeObject("InfixExpression", n.Token,
+			jsonField{"left", encodeNode(n.Left)},
+			jsonField{"operator", n.Operator},
+			jsonField{"right", encodeNode(n.Right)})
+	case *AssignExpression:
+		return nodeObject("AssignExpression", n.Token,
+			jsonField{"target", encodeNode(n.Target)},
+			jsonField{"operator", n.Operator},
+			jsonField{"value", encodeNode(n.Value)})
+	case *ConditionalExpression:
+		return nodeObject("ConditionalExpression", n.Token,
+			jsonField{"condition", encodeNode(n.Condition)},
+			jsonField{"consequence", encodeNode(n.Consequence)},
+			jsonField{"alternative", encodeNode(n.Alternative)})
+	case *IfExpression:
+		return nodeObject("IfExpression", n.Token,
+			jsonField{"condition", encodeNode(n.Condition)},
+			jsonField{"consequence", encodeBlock(n.Consequence)},
+			jsonField{"alternative", encodeBlock(n.Alternative)})
+	case *FunctionLiteral:
+		return nodeObject("FunctionLiteral", n.Token,
+			jsonField{"parameters", encodeIdentifiers(n.Parameters)},
+			jsonField{"body", encodeBlock(n.Body)},
+			jsonField{"name", n.Name})
+	case *MacroLiteral:
+		return nodeObject("MacroLiteral", n.Token,
+			jsonField{"parameters", encodeIdentifiers(n.Parameters)},
+			jsonField{"body", encodeBlock(n.Body)})
+	case *CallExpression:
+		return nodeObject("CallExpression", n.Token,
+			jsonField{"function", encodeNode(n.Function)},
+			jsonField{"arguments", encodeExpressions(n.Arguments)})
+	case *ArrayLiteral:
+		return nodeObject("ArrayLiteral", n.Token,
+			jsonField{"elements", encodeExpressions(n.Elements)})
+	case *IndexExpression:
+		return nodeObject("IndexExpression", n.Token,
+			jsonField{"left", encodeNode(n.Left)},
+			jsonField{"index", encodeNode(n.Index)})
+	case *HashLiteral:
+		var pairs []any
+		if n.Pairs != nil {
+			pairs = make([]any, len(n.Pairs))
+			for i, pair := range n.Pairs {
+				pairs[i] = jsonObject{{"key", encodeNode(pair.Key)}, {"value", encodeNode(pair.Value)}}
+			}
+		}
+		return nodeObject("HashLiteral", n.Token, jsonField{"pairs", pairs})
+	}
+
+	// nilの子
+	return nil
+}
+
+// 以下はnilのポインタやスライスをnullに変換するための補助関数
+
+func encodeIdentifier(ident *Identifier) any {
+	if ident == nil {
+		return nil
+	}
+	return encodeNode(ident)
+}
+
+func encodeBlock(block *BlockStatement) any {
+	if block == nil {
+		return nil
+	}
+	return encodeNode(block)
+}
+
+func encodeStatements(stmts []Statement) any {
+	if stmts == nil {
+		return nil
+	}
+	encoded := make([]any, len(stmts))
+	for i, stmt := range stmts {
+		encoded[i] = encodeNode(stmt)
+	}
+	return encoded
+}
+
+func encodeExpressions(exps []Expression) any {
+	if exps == nil {
+		return nil
+	}
+	encoded := make([]any, len(exps))
+	for i, exp := range exps {
+		encoded[i] = encodeNode(exp)
+	}
+	return encoded
+}
+
+func encodeIdentifiers(idents []*Identifier) any {
+	if idents == nil {
+		return nil
+	}
+	encoded := make([]any, len(idents))
+	for i, ident := range idents {
+		encoded[i] = encodeIdentifier(ident)
+	}
+	return encoded
+}
+
+// JSONからノードを組み立てながら、最初に見つかったエラーを記録する
+type decoder struct {
+	err error
+}
+
+func (d *decoder) fail(format string, args ...any) {
+	if d.err == nil {
+		d.err = fmt.Errorf("ast: "+format, args...)
+	}
+}
+
+// JSONの値をvに読み込む。値がnullまたは省略されている場合はvを変更しない
+func (d *decoder) decode(raw json.RawMessage, v any) {
+	if d.err != nil || isNull(raw) {
+		return
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		d.err = err
+	}
+}
+
+func isNull(raw json.RawMessage) bool {
+	return len(raw) == 0 || string(bytes.TrimSpace(raw)) == "null"
+}
+
+func (d *decoder) node(raw json.RawMessage) Node {
+	if d.err != nil || isNull(raw) {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	d.decode(raw, &fields)
+	var typ string
+	d.decode(fields["type"], &typ)
+	if d.err != nil {
+		return nil
+	}
+
+	switch typ {
+	// 文
+	case "Program":
+		return &Program{Statements: d.statements(fields["statements"])}
+	case "LetStatement":
+		return &LetStatement{
+			Token: d.token(fields["token"]),
+			Name:  d.identifier(fields["name"]),
+			Value: d.expression(fields["value"]),
+		}
+	case "ReturnStatement":
+		return &ReturnStatement{
+			Token:       d.token(fields["token"]),
+			ReturnValue: d.expression(fields["returnValue"]),
+		}
+	case "ExpressionStatement":
+		return &ExpressionStatement{
+			Token:      d.token(fields["token"]),
+			Expression: d.expression(fields["expression"]),
+		}
+	case "BlockStatement":
+		return &BlockStatement{
+			Token:      d.token(fields["token"]),
+			Statements: d.statements(fields["statements"]),
+		}
+	case "WhileStatement":
+		return &WhileStatement{
+			Token:     d.token(fields["token"]),
+			Condition: d.expression(fields["condition"]),
+			Body:      d.block(fields["body"]),
+		}
+	case "ForStatement":
+		return &ForStatement{
+			Token:     d.token(fields["token"]),
+			Init:      d.statement(fields["init"]),
+			Condition: d.expression(fields["condition"]),
+			Post:      d.statement(fields["post"]),
+			Body:      d.block(fields["body"]),
+		}
+	case "ForInStatement":
+		return &ForInStatement{
+			Token:    d.token(fields["token"]),
+			Variable: d.identifier(fields["variable"]),
+			Iterable: d.expression(fields["iterable"]),
+			Body:     d.block(fields["body"]),
+		}
+	case "BreakStatement":
+		return &BreakStatement{Token: d.token(fields["token"])}
+	case "ContinueStatement":
+		return &ContinueStatement{Token: d.token(fields["token"])}
+
+	// 式
+	case "Identifier":
+		n := &Identifier{Token: d.token(fields["token"])}
+		d.decode(fields["value"], &n.Value)
+		return n
+	case "IntegerLiteral":
+		n := &IntegerLiteral{Token: d.token(fields["token"])}
+		var value string
+		d.decode(fields["value"], &value)
+		if !isNull(fields["value"]) && d.err == nil {
+			v, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				d.fail("invalid integer value %q", value)
+			}
+			n.Value = v
+		}
+		return n
+	case "FloatLiteral":
+		n := &FloatLiteral{Token: d.token(fields["token"])}
+		d.decode(fields["value"], &n.Value)
+		return n
+	case "StringLiteral":
+		n := &StringLiteral{Token: d.token(fields["token"])}
+		d.decode(fields["value"], &n.Value)
+		return n
+	case "Boolean":
+		n := &Boolean{Token: d.token(fields["token"])}
+		d.decode(fields["value"], &n.Value)
+		return n
+	case "PrefixExpression":
+		n := &PrefixExpression{
+			Token: d.token(fields["token"]),
+			Right: d.expression(fields["right"]),
+		}
+		d.decode(fields["operator"], &n.Operator)
+		return n
+	case "InfixExpression":
+		n := &InfixExpression{
+			Token: d.token(fields["token"]),
+			Left:  d.expression(fields["left"]),
+			Right: d.expression(fields["right"]),
+		}
+		d.decode(fields["operator"], &n.Operator)
+		return n
+	case "AssignExpression":
+		n := &AssignExpression{
+			Token:  d.token(fields["token"]),
+			Target: d.expression(fields["target"]),
+			Value:  d.expression(fields["value"]),
+		}
+		d.decode(fields["operator"], &n.Operator)
+		return n
+	case "ConditionalExpression":
+		return &ConditionalExpression{
+			Token:       d.token(fields["token"]),
+			Condition:   d.expression(fields["condition"]),
+			Consequence: d.expression(fields["consequence"]),
+			Alternative: d.expression(fields["alternative"]),
+		}
+	case "IfExpression":
+		return &IfExpression{
+			Token:       d.token(fields["token"]),
+			Condition:   d.expression(fields["condition"]),
+			Consequence: d.block(fields["consequence"]),
+			Alternative: d.block(fields["alternative"]),
+		}
+	case "FunctionLiteral":
+		n := &FunctionLiteral{
+			Token:      d.token(fields["token"]),
+			Parameters: d.identifiers(fields["parameters"]),
+			Body:       d.block(fields["body"]),
+		}
+		d.decode(fields["name"], &n.Name)
+		return n
+	case "MacroLiteral":
+		return &MacroLiteral{
+			Token:      d.token(fields["token"]),
+			Parameters: d.identifiers(fields["parameters"]),
+			Body:       d.block(fields["body"]),
+		}
+	case "CallExpression":
+		return &CallExpression{
+			Token:     d.token(fields["token"]),
+			Function:  d.expression(fields["function"]),
+			Arguments: d.expressions(fields["arguments"]),
+		}
+	case "ArrayLiteral":
+		return &ArrayLiteral{
+			Token:    d.token(fields["token"]),
+			Elements: d.expressions(fields["elements"]),
+		}
+	case "IndexExpression":
+		return &IndexExpression{
+			Token: d.token(fields["token"]),
+			Left:  d.expression(fields["left"]),
+			Index: d.expression(fields["index"]),
+		}
+	case "HashLiteral":
+		return &HashLiteral{
+			Token: d.token(fields["token"]),
+			Pairs: d.pairs(fields["pairs"]),
+		}
+	case "":
+		d.fail("missing node type")
+	default:
+		d.fail("unknown node type %q", typ)
+	}
+	return nil
+}
+
+func (d *decoder) token(raw json.RawMessage) token.Token {
+	var tok token.Token
+	d.decode(raw, &tok)
+	return tok
+}
+
+// 以下は子を組み立てて、置ける種類のノードかどうかを検査する補助関数
+
+func (d *decoder) statement(raw json.RawMessage) Statement {
+	node := d.node(raw)
+	if node == nil {
+		return nil
+	}
+	stmt, ok := node.(Statement)
+	if !ok {
+		d.fail("cannot use %T as Statement", node)
+	}
+	return stmt
+}
+
+func (d *decoder) expression(raw json.RawMessage) Expression {
+	node := d.node(raw)
+	if node == nil {
+		return nil
+	}
+	exp, ok := node.(Expression)
+	if !ok {
+		d.fail("cannot use %T as Expression", node)
+	}
+	return exp
+}
+
+func (d *decoder) identifier(raw json.RawMessage) *Identifier {
+	node := d.node(raw)
+	if node == nil {
+		return nil
+	}
+	ident, ok := node.(*Identifier)
+	if !ok {
+		d.fail("cannot use %T as *ast.Identifier", node)
+	}
+	return ident
+}
+
+func (d *decoder) block(raw json.RawMessage) *BlockStatement {
+	node := d.node(raw)
+	if node == nil {
+		return nil
+	}
+	block, ok := node.(*BlockStatement)
+	if !ok {
+		d.fail("cannot use %T as *ast.BlockStatement", node)
+	}
+	return block
+}
+
+// JSONの配列を要素ごとのJSONに分ける。nullの場合はnilを返す
+func (d *decoder) list(raw json.RawMessage) []json.RawMessage {
+	var list []json.RawMessage
+	d.decode(raw, &list)
+	if list == nil && !isNull(raw) {
+		return []json.RawMessage{} // 空の配列
+	}
+	return list
+}
+
+func (d *decoder) statements(raw json.RawMessage) []Statement {
+	list := d.list(raw)
+	if list == nil {
+		return nil
+	}
+	stmts := make([]Statement, len(list))
+	for i, item := range list {
+		stmts[i] = d.statement(item)
+	}
+	return stmts
+}
+
+func (d *decoder) expressions(raw json.RawMessage) []Expression {
+	list := d.list(raw)
+	if list == nil {
+		return nil
+	}
+	exps := make([]Expression, len(list))
+	for i, item := range list {
+		exps[i] = d.expression(item)
+	}
+	return exps
+}
+
+func (d *decoder) identifiers(raw json.RawMessage) []*Identifier {
+	list := d.list(raw)
+	if list == nil {
+		return nil
+	}
+	idents := make([]*Identifier, len(list))
+	for i, item := range list {
+		idents[i] = d.identifier(item)
+	}
+	return idents
+}
+
+func (d *decoder) pairs(raw json.RawMessage) []*HashPair {
+	list := d.list(raw)
+	if list == nil {
+		return nil
+	}
+	pairs := make([]*HashPair, len(list))
+	for i, item := range list {
+		var fields map[string]json.RawMessage
+		d.decode(item, &fields)
+		pairs[i] = &HashPair{
+			Key:   d.expression(fields["key"]),
+			Value: d.expression(fields["value"]),
+		}
+	}
+	return pairs
+}
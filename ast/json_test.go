@@ -0,0 +1,230 @@
+package ast_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/mahiro72/monkey-lang/ast"
+	"github.com/mahiro72/monkey-lang/lexer"
+	"github.com/mahiro72/monkey-lang/parser"
+	testingHelper "github.com/mahiro72/monkey-lang/testing"
+)
+
+func TestMarshal(t *testing.T) {
+	program := parse(t, "-x")
+
+	data, err := ast.Marshal(program)
+	testingHelper.AssertEqual(t, nil, err)
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		t.Fatal(err)
+	}
+	testingHelper.AssertEqual(t, `{
+  "type": "Program",
+  "statements": [
+    {
+      "type": "ExpressionStatement",
+      "token": {
+        "type": "-",
+        "literal": "-",
+        "pos": {
+          "offset": 0,
+          "line": 1,
+          "column": 1
+        },
+        "end": {
+          "offset": 1,
+          "line": 1,
+          "column": 2
+        }
+      },
+      "expression": {
+        "type": "PrefixExpression",
+        "token": {
+          "type": "-",
+          "literal": "-",
+          "pos": {
+            "offset": 0,
+            "line": 1,
+            "column": 1
+          },
+          "end": {
+            "offset": 1,
+            "line": 1,
+            "column": 2
+          }
+        },
+        "operator": "-",
+        "right": {
+          "type": "Identifier",
+          "token": {
+            "type": "IDENT",
+            "literal": "x",
+            "pos": {
+              "offset": 1,
+              "line": 1,
+              "column": 2
+            },
+            "end": {
+              "offset": 2,
+              "line": 1,
+              "column": 3
+            }
+          },
+          "value": "x"
+        }
+      }
+    }
+  ]
+}`, out.String())
+}
+
+// 整数は2^53を超えても丸められないよう文字列で出力する
+func TestMarshalIntegerAsString(t *testing.T) {
+	program := parse(t, "9007199254740993")
+
+	data, err := ast.Marshal(program.Statements[0].(*ast.ExpressionStatement).Expression)
+	testingHelper.AssertEqual(t, nil, err)
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	testingHelper.AssertEqual(t, any("9007199254740993"), fields["value"])
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "success: 全ての種類のノード", input: allNodesInput},
+		{name: "success: 省略された子", input: `if (a) { b } for (;;) {}`},
+		{name: "success: 空のリスト", input: `fn() {}(); [];{}`},
+		{name: "success: 文字列と数値", input: `"a\"\n\t🐒"; 9223372036854775807; 0.1; 1e-9`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.New(lexer.NewWithFilename("main.mk", tt.input))
+			program := p.ParseProgram()
+			testingHelper.AssertEqual(t, []string{}, p.Errors())
+
+			data, err := ast.Marshal(program)
+			testingHelper.AssertEqual(t, nil, err)
+			node, err := ast.Unmarshal(data)
+			testingHelper.AssertEqual(t, nil, err)
+
+			// 位置も含めて全てのフィールドが元に戻る
+			testingHelper.AssertEqual(t, ast.Node(program), node)
+		})
+	}
+}
+
+// 全ての種類のノードについて、単体でもJSONとの変換で元に戻る
+func TestJSONRoundTripEachNode(t *testing.T) {
+	program := parse(t, allNodesInput)
+
+	kinds := map[string]bool{}
+	ast.Inspect(program, func(node ast.Node) bool {
+		if node == nil {
+			return true
+		}
+		kinds[fmt.Sprintf("%T", node)] = true
+
+		data, err := ast.Marshal(node)
+		testingHelper.AssertEqual(t, nil, err)
+		got, err := ast.Unmarshal(data)
+		testingHelper.AssertEqual(t, nil, err)
+		testingHelper.AssertEqual(t, node, got)
+		return true
+	})
+
+	// TestWalkVisitsEveryNodeOnceで列挙している全ての種類
+	testingHelper.AssertEqual(t, 26, len(kinds))
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expectedError string
+	}{
+		{
+			name:          "failure: 不正なJSON",
+			input:         `{"type": `,
+			expectedError: "unexpected end of JSON input",
+		},
+		{
+			name:          "failure: 型名が無い",
+			input:         `{"value": "x"}`,
+			expectedError: "ast: missing node type",
+		},
+		{
+			name:          "failure: 未知の型名",
+			input:         `{"type": "GotoStatement"}`,
+			expectedError: `ast: unknown node type "GotoStatement"`,
+		},
+		{
+			name:          "failure: 式の位置に文",
+			input:         `{"type": "PrefixExpression", "operator": "-", "right": {"type": "BreakStatement"}}`,
+			expectedError: "ast: cannot use *ast.BreakStatement as Expression",
+		},
+		{
+			name:          "failure: ブロックの位置にブロック以外",
+			input:         `{"type": "WhileStatement", "condition": {"type": "Boolean", "value": true}, "body": {"type": "BreakStatement"}}`,
+			expectedError: "ast: cannot use *ast.BreakStatement as *ast.BlockStatement",
+		},
+		{
+			name:          "failure: 仮引数の位置に識別子以外",
+			input:         `{"type": "FunctionLiteral", "parameters": [{"type": "IntegerLiteral", "value": "1"}]}`,
+			expectedError: "ast: cannot use *ast.IntegerLiteral as *ast.Identifier",
+		},
+		{
+			name:          "failure: フィールドの型が異なる",
+			input:         `{"type": "Identifier", "value": 1}`,
+			expectedError: "json: cannot unmarshal number into Go value of type string",
+		},
+		{
+			name:          "failure: 整数の値は文字列で表す",
+			input:         `{"type": "IntegerLiteral", "value": 1}`,
+			expectedError: "json: cannot unmarshal number into Go value of type string",
+		},
+		{
+			name:          "failure: 整数として読めない値",
+			input:         `{"type": "IntegerLiteral", "value": "0x10"}`,
+			expectedError: `ast: invalid integer value "0x10"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := ast.Unmarshal([]byte(tt.input))
+			testingHelper.AssertEqual(t, nil, node)
+			testingHelper.AssertEqual(t, tt.expectedError, err.Error())
+		})
+	}
+}
+
+// nilのリストと空のリストは区別して元に戻る
+func TestJSONRoundTripNilLists(t *testing.T) {
+	nodes := []ast.Node{
+		&ast.Program{},
+		&ast.HashLiteral{},
+		&ast.HashLiteral{Pairs: []*ast.HashPair{}},
+		&ast.ArrayLiteral{},
+		&ast.FunctionLiteral{Parameters: []*ast.Identifier{}},
+		&ast.CallExpression{Arguments: nil},
+	}
+
+	for _, node := range nodes {
+		data, err := ast.Marshal(node)
+		testingHelper.AssertEqual(t, nil, err)
+		got, err := ast.Unmarshal(data)
+		testingHelper.AssertEqual(t, nil, err)
+		testingHelper.AssertEqual(t, node, got)
+	}
+}
@@ -0,0 +1,236 @@
+package ast
+
+import "fmt"
+
+// Modify, Rewriteでノードを置き換える関数。置き換えない場合は受け取ったノードをそのまま返す
+type ModifierFunc func(Node) Node
+
+// 木を帰りがけ順に辿り、子を置き換えた後のノードをmodifierに渡して置き換える
+// 子を持つノードは複製してから子を差し替えるので、元の木は変更されない
+// (同じ関数本体を何度もquoteしても、前回の置き換えが残らないようにするため)
+// 置き換え先に置けない種類のノードが返された場合はnilに置き換わる。検査が必要な場合はRewriteを使う
+func Modify(node Node, modifier ModifierFunc) Node {
+	r := &rewriter{fn: modifier}
+	return r.node(node)
+}
+
+// Modifyと同じく木を帰りがけ順に置き換えるが、置き換え先に置けないノードが返された場合はエラーを返す
+// (式の位置に文を返した場合や、ブロックの位置にブロック以外を返した場合など)
+// 文の並び (Program.StatementsやBlockStatement.Statements) の中の文をnilに置き換えると、その文は取り除かれる
+func Rewrite(node Node, rewrite ModifierFunc) (Node, error) {
+	r := &rewriter{fn: rewrite}
+	rewritten := r.node(node)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return rewritten, nil
+}
+
+// 置き換えの途中で見つかった最初のエラーを記録しながら木を組み立て直す
+type rewriter struct {
+	fn  ModifierFunc
+	err error
+}
+
+func (r *rewriter) node(node Node) Node {
+	switch node := node.(type) {
+	// 文
+	case *Program:
+		n := *node
+		n.Statements = r.statements(node.Statements)
+		return r.fn(&n)
+	case *ExpressionStatement:
+		n := *node
+		n.Expression = r.expression(node.Expression)
+		return r.fn(&n)
+	case *LetStatement:
+		n := *node
+		n.Name = r.identifier(node.Name)
+		n.Value = r.expression(node.Value)
+		return r.fn(&n)
+	case *ReturnStatement:
+		n := *node
+		n.ReturnValue = r.expression(node.ReturnValue)
+		return r.fn(&n)
+	case *BlockStatement:
+		n := *node
+		n.Statements = r.statements(node.Statements)
+		return r.fn(&n)
+	case *WhileStatement:
+		n := *node
+		n.Condition = r.expression(node.Condition)
+		n.Body = r.block(node.Body)
+		return r.fn(&n)
+	case *ForStatement:
+		n := *node
+		n.Init = r.statement(node.Init)
+		n.Condition = r.expression(node.Condition)
+		n.Post = r.statement(node.Post)
+		n.Body = r.block(node.Body)
+		return r.fn(&n)
+	case *ForInStatement:
+		n := *node
+		n.Variable = r.identifier(node.Variable)
+		n.Iterable = r.expression(node.Iterable)
+		n.Body = r.block(node.Body)
+		return r.fn(&n)
+
+	// 式
+	case *PrefixExpression:
+		n := *node
+		n.Right = r.expression(node.Right)
+		return r.fn(&n)
+	case *InfixExpression:
+		n := *node
+		n.Left = r.expression(node.Left)
+		n.Right = r.expression(node.Right)
+		return r.fn(&n)
+	case *AssignExpression:
+		n := *node
+		n.Target = r.expression(node.Target)
+		n.Value = r.expression(node.Value)
+		return r.fn(&n)
+	case *ConditionalExpression:
+		n := *node
+		n.Condition = r.expression(node.Condition)
+		n.Consequence = r.expression(node.Consequence)
+		n.Alternative = r.expression(node.Alternative)
+		return r.fn(&n)
+	case *IfExpression:
+		n := *node
+		n.Condition = r.expression(node.Condition)
+		n.Consequence = r.block(node.Consequence)
+		n.Alternative = r.block(node.Alternative)
+		return r.fn(&n)
+	case *IndexExpression:
+		n := *node
+		n.Left = r.expression(node.Left)
+		n.Index = r.expression(node.Index)
+		return r.fn(&n)
+	case *FunctionLiteral:
+		n := *node
+		n.Parameters = r.identifiers(node.Parameters)
+		n.Body = r.block(node.Body)
+		return r.fn(&n)
+	case *MacroLiteral:
+		n := *node
+		n.Parameters = r.identifiers(node.Parameters)
+		n.Body = r.block(node.Body)
+		return r.fn(&n)
+	case *CallExpression:
+		n := *node
+		n.Function = r.expression(node.Function)
+		n.Arguments = r.expressions(node.Arguments)
+		return r.fn(&n)
+	case *ArrayLiteral:
+		n := *node
+		n.Elements = r.expressions(node.Elements)
+		return r.fn(&n)
+	case *HashLiteral:
+		n := *node
+		n.Pairs = make([]*HashPair, len(node.Pairs))
+		for i, pair := range node.Pairs {
+			n.Pairs[i] = &HashPair{
+				Key:   r.expression(pair.Key),
+				Value: r.expression(pair.Value),
+			}
+		}
+		return r.fn(&n)
+	}
+
+	// 子を持たないノード (識別子やリテラル, break, continue)
+	return r.fn(node)
+}
+
+// 置き換え先に置けないノードが返された場合のエラーを記録する
+func (r *rewriter) mismatch(original, replaced Node, want string) {
+	if r.err == nil {
+		r.err = fmt.Errorf("ast: cannot replace %T with %T: want %s", original, replaced, want)
+	}
+}
+
+// 以下は子を置き換えるための補助関数。子がnilの場合はnilのまま残す
+
+func (r *rewriter) statement(stmt Statement) Statement {
+	if stmt == nil {
+		return nil
+	}
+	replaced := r.node(stmt)
+	modified, ok := replaced.(Statement)
+	if !ok && replaced != nil {
+		r.mismatch(stmt, replaced, "Statement")
+	}
+	return modified
+}
+
+func (r *rewriter) expression(exp Expression) Expression {
+	if exp == nil {
+		return nil
+	}
+	replaced := r.node(exp)
+	modified, ok := replaced.(Expression)
+	if !ok {
+		r.mismatch(exp, replaced, "Expression")
+	}
+	return modified
+}
+
+func (r *rewriter) identifier(ident *Identifier) *Identifier {
+	if ident == nil {
+		return nil
+	}
+	replaced := r.node(ident)
+	modified, ok := replaced.(*Identifier)
+	if !ok {
+		r.mismatch(ident, replaced, "*ast.Identifier")
+	}
+	return modified
+}
+
+func (r *rewriter) block(block *BlockStatement) *BlockStatement {
+	if block == nil {
+		return nil
+	}
+	replaced := r.node(block)
+	modified, ok := replaced.(*BlockStatement)
+	if !ok {
+		r.mismatch(block, replaced, "*ast.BlockStatement")
+	}
+	return modified
+}
+
+// nilに置き換えられた文は並びから取り除く
+func (r *rewriter) statements(stmts []Statement) []Statement {
+	if stmts == nil {
+		return nil
+	}
+	modified := make([]Statement, 0, len(stmts))
+	for _, stmt := range stmts {
+		if stmt := r.statement(stmt); stmt != nil {
+			modified = append(modified, stmt)
+		}
+	}
+	return modified
+}
+
+func (r *rewriter) expressions(exps []Expression) []Expression {
+	if exps == nil {
+		return nil
+	}
+	modified := make([]Expression, len(exps))
+	for i, exp := range exps {
+		modified[i] = r.expression(exp)
+	}
+	return modified
+}
+
+func (r *rewriter) identifiers(idents []*Identifier) []*Identifier {
+	if idents == nil {
+		return nil
+	}
+	modified := make([]*Identifier, len(idents))
+	for i, ident := range idents {
+		modified[i] = r.identifier(ident)
+	}
+	return modified
+}
@@ -0,0 +1,261 @@
+package ast_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mahiro72/monkey-lang/ast"
+	"github.com/mahiro72/monkey-lang/lexer"
+	"github.com/mahiro72/monkey-lang/parser"
+	testingHelper "github.com/mahiro72/monkey-lang/testing"
+	"github.com/mahiro72/monkey-lang/token"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	testingHelper.AssertEqual(t, []string{}, p.Errors())
+	return program
+}
+
+// 整数リテラルの1を2に置き換える
+func turnOneIntoTwo(node ast.Node) ast.Node {
+	integer, ok := node.(*ast.IntegerLiteral)
+	if !ok || integer.Value != 1 {
+		return node
+	}
+	return &ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "2"}, Value: 2}
+}
+
+func TestModify(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "success: 式文", input: `1`, expected: `2`},
+		{name: "success: 前置式", input: `-1`, expected: `-2`},
+		{name: "success: 中置式", input: `1 + 2; 2 * 1`, expected: `2 + 2; 2 * 2`},
+		{name: "success: 添字アクセス", input: `[1][1]`, expected: `[2][2]`},
+		{name: "success: if式", input: `if (1) { 1 } else { 1 }`, expected: `if (2) { 2 } else { 2 }`},
+		{name: "success: else if", input: `if (1) { 1 } else if (1) { 1 }`, expected: `if (2) { 2 } else if (2) { 2 }`},
+		{name: "success: return文", input: `fn() { return 1; }`, expected: `fn() { return 2; }`},
+		{name: "success: let文", input: `let x = 1;`, expected: `let x = 2;`},
+		{name: "success: 関数リテラル", input: `fn(x) { 1 }`, expected: `fn(x) { 2 }`},
+		{name: "success: マクロリテラル", input: `macro(x) { 1 }`, expected: `macro(x) { 2 }`},
+		{name: "success: 関数呼び出し", input: `f(1, g(1))`, expected: `f(2, g(2))`},
+		{name: "success: 配列リテラル", input: `[1, 1]`, expected: `[2, 2]`},
+		{name: "success: ハッシュリテラル", input: `{1: 1, "a": 1}`, expected: `{2: 2, "a": 2}`},
+		{name: "success: 代入式", input: `x[1] += 1`, expected: `x[2] += 2`},
+		{name: "success: 条件演算子", input: `1 ? 1 : 1`, expected: `2 ? 2 : 2`},
+		{name: "success: while文", input: `while (1) { 1; break; }`, expected: `while (2) { 2; break; }`},
+		{name: "success: for文", input: `for (let i = 1; i < 1; i += 1) { continue; }`, expected: `for (let i = 2; i < 2; i += 2) { continue; }`},
+		{name: "success: for-in文", input: `for (x in [1]) { 1 }`, expected: `for (x in [2]) { 2 }`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := parse(t, tt.input)
+			expected := parse(t, tt.expected)
+
+			modified := ast.Modify(program, turnOneIntoTwo)
+			testingHelper.AssertEqual(t, expected.String(), modified.String())
+		})
+	}
+}
+
+func TestModifyDoesNotChangeOriginal(t *testing.T) {
+	program := parse(t, `let f = fn(x) { x + 1 }; f(1)`)
+	before := program.String()
+
+	modified := ast.Modify(program, turnOneIntoTwo)
+
+	testingHelper.AssertEqual(t, before, program.String())
+	testingHelper.AssertEqual(t, "let f = fn(x) (x + 2);f(2)", modified.String())
+}
+
+func TestModifyStatement(t *testing.T) {
+	program := parse(t, `1; 2;`)
+
+	// 式文を丸ごとlet文に置き換える
+	modified := ast.Modify(program, func(node ast.Node) ast.Node {
+		stmt, ok := node.(*ast.ExpressionStatement)
+		if !ok {
+			return node
+		}
+		return &ast.LetStatement{
+			Token: token.Token{Type: token.LET, Literal: "let"},
+			Name:  &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: "x"}, Value: "x"},
+			Value: stmt.Expression,
+		}
+	})
+
+	testingHelper.AssertEqual(t, "let x = 1;let x = 2;", modified.String())
+}
+
+// 整数同士の加算と乗算を畳み込む
+func foldConstants(node ast.Node) ast.Node {
+	infix, ok := node.(*ast.InfixExpression)
+	if !ok {
+		return node
+	}
+	left, ok := infix.Left.(*ast.IntegerLiteral)
+	if !ok {
+		return node
+	}
+	right, ok := infix.Right.(*ast.IntegerLiteral)
+	if !ok {
+		return node
+	}
+
+	var value int64
+	switch infix.Operator {
+	case "+":
+		value = left.Value + right.Value
+	case "*":
+		value = left.Value * right.Value
+	default:
+		return node
+	}
+	return &ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: fmt.Sprint(value)}, Value: value}
+}
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		rewrite  ast.ModifierFunc
+		expected string
+	}{
+		{
+			name:     "success: 定数の畳み込みは内側から行われる",
+			input:    `let x = 1 + 2 * 3; f(2 * 2, x + 1)`,
+			rewrite:  foldConstants,
+			expected: "let x = 7;f(4, (x + 1))",
+		},
+		{
+			name:     "success: ifの分岐の中も置き換える",
+			input:    `if (1 + 1) { 2 * 3 } else { 4 + 5 }`,
+			rewrite:  foldConstants,
+			expected: "if 2 { 6 } else { 9 }",
+		},
+		{
+			name:  "success: nilに置き換えた文は取り除かれる",
+			input: `let a = 1; debug(a); let b = 2; fn() { debug(b); b }`,
+			rewrite: func(node ast.Node) ast.Node {
+				stmt, ok := node.(*ast.ExpressionStatement)
+				if !ok {
+					return node
+				}
+				if call, ok := stmt.Expression.(*ast.CallExpression); ok && call.Function.String() == "debug" {
+					return nil
+				}
+				return node
+			},
+			expected: "let a = 1;let b = 2;fn() b",
+		},
+		{
+			name:  "success: 脱糖 (x += y を x = x + y に置き換える)",
+			input: `x += 1`,
+			rewrite: func(node ast.Node) ast.Node {
+				assign, ok := node.(*ast.AssignExpression)
+				if !ok || assign.Operator != "+=" {
+					return node
+				}
+				return &ast.AssignExpression{
+					Token:    token.Token{Type: token.ASSIGN, Literal: "="},
+					Target:   assign.Target,
+					Operator: "=",
+					Value: &ast.InfixExpression{
+						Token:    token.Token{Type: token.PLUS, Literal: "+"},
+						Left:     assign.Target,
+						Operator: "+",
+						Right:    assign.Value,
+					},
+				}
+			},
+			expected: "(x = (x + 1))",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := parse(t, tt.input)
+			before := program.String()
+
+			rewritten, err := ast.Rewrite(program, tt.rewrite)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			testingHelper.AssertEqual(t, tt.expected, rewritten.String())
+			testingHelper.AssertEqual(t, before, program.String())
+		})
+	}
+}
+
+func TestRewriteErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		rewrite       ast.ModifierFunc
+		expectedError string
+	}{
+		{
+			name:  "failure: 式の位置に文",
+			input: `f(x)`,
+			rewrite: func(node ast.Node) ast.Node {
+				if _, ok := node.(*ast.Identifier); ok {
+					return &ast.BreakStatement{Token: token.Token{Type: token.BREAK, Literal: "break"}}
+				}
+				return node
+			},
+			expectedError: "ast: cannot replace *ast.Identifier with *ast.BreakStatement: want Expression",
+		},
+		{
+			name:  "failure: 式をnilに置き換える",
+			input: `1 + 2`,
+			rewrite: func(node ast.Node) ast.Node {
+				if _, ok := node.(*ast.IntegerLiteral); ok {
+					return nil
+				}
+				return node
+			},
+			expectedError: "ast: cannot replace *ast.IntegerLiteral with <nil>: want Expression",
+		},
+		{
+			name:  "failure: ブロックの位置にブロック以外",
+			input: `if (a) { b }`,
+			rewrite: func(node ast.Node) ast.Node {
+				if block, ok := node.(*ast.BlockStatement); ok {
+					return block.Statements[0]
+				}
+				return node
+			},
+			expectedError: "ast: cannot replace *ast.BlockStatement with *ast.ExpressionStatement: want *ast.BlockStatement",
+		},
+		{
+			name:  "failure: 仮引数の位置に識別子以外",
+			input: `fn(x) { x }`,
+			rewrite: func(node ast.Node) ast.Node {
+				if ident, ok := node.(*ast.Identifier); ok {
+					return &ast.StringLiteral{Token: token.Token{Type: token.STRING, Literal: ident.Value}, Value: ident.Value}
+				}
+				return node
+			},
+			expectedError: "ast: cannot replace *ast.Identifier with *ast.StringLiteral: want *ast.Identifier",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := parse(t, tt.input)
+
+			rewritten, err := ast.Rewrite(program, tt.rewrite)
+			if err == nil {
+				t.Fatalf("expected error, got %s", rewritten.String())
+			}
+			testingHelper.AssertEqual(t, tt.expectedError, err.Error())
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package ast
+
+// Walkで各ノードを訪れる際に呼ばれるVisitメソッドを持つ
+// Visitが返したVisitor wがnilでなければ、Walkはノードの子をそれぞれwで訪れた後、w.Visit(nil)を呼ぶ
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// 木を深さ優先で辿る。まずv.Visit(node)を呼び、返されたVisitorで子を順に辿る
+// 子の順序はソース上の記述順で、nilの子 (省略されたelseやfor文の初期化など) は訪れない
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	// 文
+	case *Program:
+		walkStatements(v, n.Statements)
+	case *LetStatement:
+		Walk(v, n.Name)
+		walkExpression(v, n.Value)
+	case *ReturnStatement:
+		walkExpression(v, n.ReturnValue)
+	case *ExpressionStatement:
+		walkExpression(v, n.Expression)
+	case *BlockStatement:
+		walkStatements(v, n.Statements)
+	case *WhileStatement:
+		walkExpression(v, n.Condition)
+		Walk(v, n.Body)
+	case *ForStatement:
+		walkStatement(v, n.Init)
+		walkExpression(v, n.Condition)
+		walkStatement(v, n.Post)
+		Walk(v, n.Body)
+	case *ForInStatement:
+		Walk(v, n.Variable)
+		walkExpression(v, n.Iterable)
+		Walk(v, n.Body)
+	case *BreakStatement, *ContinueStatement:
+		// 子を持たない
+
+	// 式
+	case *Identifier, *IntegerLiteral, *FloatLiteral, *StringLiteral, *Boolean:
+		// 子を持たない
+	case *PrefixExpression:
+		walkExpression(v, n.Right)
+	case *InfixExpression:
+		walkExpression(v, n.Left)
+		walkExpression(v, n.Right)
+	case *AssignExpression:
+		walkExpression(v, n.Target)
+		walkExpression(v, n.Value)
+	case *ConditionalExpression:
+		walkExpression(v, n.Condition)
+		walkExpression(v, n.Consequence)
+		walkExpression(v, n.Alternative)
+	case *IfExpression:
+		walkExpression(v, n.Condition)
+		Walk(v, n.Consequence)
+		if n.Alternative != nil {
+			Walk(v, n.Alternative)
+		}
+	case *FunctionLiteral:
+		walkIdentifiers(v, n.Parameters)
+		Walk(v, n.Body)
+	case *MacroLiteral:
+		walkIdentifiers(v, n.Parameters)
+		Walk(v, n.Body)
+	case *CallExpression:
+		walkExpression(v, n.Function)
+		walkExpressions(v, n.Arguments)
+	case *ArrayLiteral:
+		walkExpressions(v, n.Elements)
+	case *IndexExpression:
+		walkExpression(v, n.Left)
+		walkExpression(v, n.Index)
+	case *HashLiteral:
+		for _, pair := range n.Pairs {
+			walkExpression(v, pair.Key)
+			walkExpression(v, pair.Value)
+		}
+	}
+
+	v.Visit(nil)
+}
+
+// 関数をVisitorとして使うためのアダプタ
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// 木を深さ優先で辿り、各ノードでf(node)を呼ぶ。fがfalseを返した場合はそのノードの子を辿らない
+// 子を辿り終えた後にf(nil)が呼ばれる
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+func walkStatement(v Visitor, stmt Statement) {
+	if stmt != nil {
+		Walk(v, stmt)
+	}
+}
+
+func walkExpression(v Visitor, exp Expression) {
+	if exp != nil {
+		Walk(v, exp)
+	}
+}
+
+func walkStatements(v Visitor, stmts []Statement) {
+	for _, stmt := range stmts {
+		walkStatement(v, stmt)
+	}
+}
+
+func walkExpressions(v Visitor, exps []Expression) {
+	for _, exp := range exps {
+		walkExpression(v, exp)
+	}
+}
+
+func walkIdentifiers(v Visitor, idents []*Identifier) {
+	for _, ident := range idents {
+		if ident != nil {
+			Walk(v, ident)
+		}
+	}
+}
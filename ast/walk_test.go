@@ -0,0 +1,186 @@
+package ast_test
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/mahiro72/monkey-lang/ast"
+	testingHelper "github.com/mahiro72/monkey-lang/testing"
+)
+
+// 全ての種類のノードを含むプログラム
+const allNodesInput = `
+	let add = fn(x, y) { return x + y; };
+	let m = macro(a) { quote(unquote(a)) };
+	let h = {"k": [1, 2.5, true][0]};
+	h["k"] += -1;
+	let v = h["k"] > 0 ? "pos" : h["x"] ?? "none";
+	if (v == "pos") { add(1, 2) } else if (false) { 0 } else { 1 }
+	while (true) { break; }
+	for (let i = 0; i < 3; i = i + 1) { continue; }
+	for (x in [1]) { x }
+`
+
+// reflectで木を辿り、Walkとは独立に到達できる全てのノードを集める
+func collectNodes(v reflect.Value, nodes map[ast.Node]bool) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if !v.IsNil() {
+			collectNodes(v.Elem(), nodes)
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		if node, ok := v.Interface().(ast.Node); ok {
+			nodes[node] = true
+		}
+		collectNodes(v.Elem(), nodes)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			collectNodes(v.Index(i), nodes)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				collectNodes(v.Field(i), nodes)
+			}
+		}
+	}
+}
+
+func TestWalkVisitsEveryNodeOnce(t *testing.T) {
+	program := parse(t, allNodesInput)
+
+	visits := map[ast.Node]int{}
+	kinds := map[string]bool{}
+	ast.Inspect(program, func(node ast.Node) bool {
+		if node != nil {
+			visits[node]++
+			kinds[fmt.Sprintf("%T", node)] = true
+		}
+		return true
+	})
+
+	for node, count := range visits {
+		if count != 1 {
+			t.Errorf("%T %q visited %d times", node, node.String(), count)
+		}
+	}
+
+	reachable := map[ast.Node]bool{}
+	collectNodes(reflect.ValueOf(program), reachable)
+	testingHelper.AssertEqual(t, len(reachable), len(visits))
+	for node := range reachable {
+		if visits[node] == 0 {
+			t.Errorf("%T %q not visited", node, node.String())
+		}
+	}
+
+	var visitedKinds []string
+	for kind := range kinds {
+		visitedKinds = append(visitedKinds, kind)
+	}
+	sort.Strings(visitedKinds)
+	testingHelper.AssertEqual(t, []string{
+		"*ast.ArrayLiteral",
+		"*ast.AssignExpression",
+		"*ast.BlockStatement",
+		"*ast.Boolean",
+		"*ast.BreakStatement",
+		"*ast.CallExpression",
+		"*ast.ConditionalExpression",
+		"*ast.ContinueStatement",
+		"*ast.ExpressionStatement",
+		"*ast.FloatLiteral",
+		"*ast.ForInStatement",
+		"*ast.ForStatement",
+		"*ast.FunctionLiteral",
+		"*ast.HashLiteral",
+		"*ast.Identifier",
+		"*ast.IfExpression",
+		"*ast.IndexExpression",
+		"*ast.InfixExpression",
+		"*ast.IntegerLiteral",
+		"*ast.LetStatement",
+		"*ast.MacroLiteral",
+		"*ast.PrefixExpression",
+		"*ast.Program",
+		"*ast.ReturnStatement",
+		"*ast.StringLiteral",
+		"*ast.WhileStatement",
+	}, visitedKinds)
+}
+
+func TestInspectOrder(t *testing.T) {
+	program := parse(t, `let x = f(a, 1) + -b;`)
+
+	var visited []string
+	ast.Inspect(program, func(node ast.Node) bool {
+		if node != nil {
+			visited = append(visited, fmt.Sprintf("%T %s", node, node.String()))
+		}
+		return true
+	})
+
+	testingHelper.AssertEqual(t, []string{
+		"*ast.Program let x = (f(a, 1) + (-b));",
+		"*ast.LetStatement let x = (f(a, 1) + (-b));",
+		"*ast.Identifier x",
+		"*ast.InfixExpression (f(a, 1) + (-b))",
+		"*ast.CallExpression f(a, 1)",
+		"*ast.Identifier f",
+		"*ast.Identifier a",
+		"*ast.IntegerLiteral 1",
+		"*ast.PrefixExpression (-b)",
+		"*ast.Identifier b",
+	}, visited)
+}
+
+func TestInspectSkipsChildren(t *testing.T) {
+	program := parse(t, `let f = fn(x) { x + 1 }; f(2)`)
+
+	var identifiers []string
+	ast.Inspect(program, func(node ast.Node) bool {
+		if _, ok := node.(*ast.FunctionLiteral); ok {
+			return false // 関数の中は辿らない
+		}
+		if ident, ok := node.(*ast.Identifier); ok {
+			identifiers = append(identifiers, ident.Value)
+		}
+		return true
+	})
+
+	testingHelper.AssertEqual(t, []string{"f", "f"}, identifiers)
+}
+
+// 子を辿り終えるとVisit(nil)が呼ばれるので、深さを数えられる
+type depthVisitor struct {
+	depth    *int
+	maxDepth *int
+}
+
+func (v depthVisitor) Visit(node ast.Node) ast.Visitor {
+	if node == nil {
+		*v.depth--
+		return nil
+	}
+	*v.depth++
+	if *v.depth > *v.maxDepth {
+		*v.maxDepth = *v.depth
+	}
+	return v
+}
+
+func TestWalkVisitNil(t *testing.T) {
+	program := parse(t, `if (a) { b }`)
+
+	depth, maxDepth := 0, 0
+	ast.Walk(depthVisitor{depth: &depth, maxDepth: &maxDepth}, program)
+
+	testingHelper.AssertEqual(t, 0, depth)
+	// Program > ExpressionStatement > IfExpression > BlockStatement > ExpressionStatement > Identifier
+	testingHelper.AssertEqual(t, 6, maxDepth)
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// 差分の前後に表示する変更の無い行数
+const diffContext = 3
+
+type diffOp struct {
+	kind byte // ' ', '-', '+'
+	line string
+}
+
+// 行単位のunified形式の差分を書き出す。内容が同じ場合は何も書き出さない
+func unifiedDiff(w io.Writer, oldName, newName, oldText, newText string) {
+	if oldText == newText {
+		return
+	}
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", oldName, newName)
+	for start := 0; start < len(ops); {
+		// 次の変更を探す
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// 変更の間の変更の無い行が2*diffContext以下なら同じハンクにまとめる
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			same := end
+			for same < len(ops) && ops[same].kind == ' ' {
+				same++
+			}
+			if same == len(ops) || same-end > 2*diffContext {
+				break
+			}
+			end = same
+		}
+
+		from := max(start-diffContext, 0)
+		to := min(end+diffContext, len(ops))
+		writeHunk(w, ops, from, to)
+		start = to
+	}
+}
+
+func writeHunk(w io.Writer, ops []diffOp, from, to int) {
+	// ハンクの開始行番号を数える (1始まり)
+	oldLine, newLine := 1, 1
+	for _, op := range ops[:from] {
+		if op.kind != '+' {
+			oldLine++
+		}
+		if op.kind != '-' {
+			newLine++
+		}
+	}
+	oldCount, newCount := 0, 0
+	for _, op := range ops[from:to] {
+		if op.kind != '+' {
+			oldCount++
+		}
+		if op.kind != '-' {
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(w, "@@ -%s +%s @@\n", hunkRange(oldLine, oldCount), hunkRange(newLine, newCount))
+	for _, op := range ops[from:to] {
+		fmt.Fprintf(w, "%c%s\n", op.kind, op.line)
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		// 空の範囲は直前の行番号で表す
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// 末尾に改行の無い最終行は、改行のある同じ内容の行とは別の行として扱い、diffと同じ注記を付ける
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	if !strings.HasSuffix(s, "\n") {
+		lines[len(lines)-1] += "\n\\ No newline at end of file"
+	}
+	return lines
+}
+
+// aをbに変える操作の列を求める
+// 共通の先頭と末尾を除いた残りを、線形のメモリで動くMyersのアルゴリズム (中間のスネークで分割統治する) で比較する
+func diffLines(a, b []string) []diffOp {
+	ops := diffRange(a, b, nil)
+
+	// 連続した変更の中では、削除を追加より先に並べる
+	result := make([]diffOp, 0, len(ops))
+	var deleted, inserted []diffOp
+	flush := func() {
+		result = append(result, deleted...)
+		result = append(result, inserted...)
+		deleted, inserted = deleted[:0], inserted[:0]
+	}
+	for _, op := range ops {
+		switch op.kind {
+		case '-':
+			deleted = append(deleted, op)
+		case '+':
+			inserted = append(inserted, op)
+		default:
+			flush()
+			result = append(result, op)
+		}
+	}
+	flush()
+	return result
+}
+
+func diffRange(a, b []string, ops []diffOp) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	a, b = a[prefix:], b[prefix:]
+
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	if x, y := middleSnake(a, b); x >= 0 {
+		ops = diffRange(a[:x], b[:y], ops)
+		ops = diffRange(a[x:], b[y:], ops)
+	} else {
+		// 共通の行が無い (片方が空の場合も含む)
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+	}
+
+	for _, line := range common {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// 最短の編集経路の途中の点 (a[:x]とb[:y]、a[x:]とb[y:]に分けて比較できる位置) を、
+// 前と後ろの両方向から探索して求める。共通の行が無い場合は(-1, -1)を返す
+// 呼び出し側で共通の先頭と末尾を除いているので、返す位置は必ず両端以外になり分割が進む
+func middleSnake(a, b []string) (int, int) {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return -1, -1
+	}
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	// forward[offset+k]は、前から探索した対角線kの上で到達した最も遠いx
+	// backward[offset+k]は、後ろから探索した対角線kの上で到達した最も遠いx (末尾からの距離)
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	delta := n - m
+	// 差が奇数なら前からの探索で、偶数なら後ろからの探索で経路が重なる
+	oddDelta := delta%2 != 0
+	// 探索が表の外に出た対角線を次から除くための幅
+	fStart, fEnd, bStart, bEnd := 0, 0, 0, 0
+
+	for d := 0; d < maxD; d++ {
+		for k := -d + fStart; k <= d-fEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -d || (k != d && forward[i-1] < forward[i+1]) {
+				x = forward[i+1]
+			} else {
+				x = forward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[i] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case oddDelta:
+				j := offset + delta - k
+				if j >= 0 && j < len(backward) && backward[j] != -1 && x >= n-backward[j] {
+					return x, y
+				}
+			}
+		}
+
+		for k := -d + bStart; k <= d-bEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -d || (k != d && backward[i-1] < backward[i+1]) {
+				x = backward[i+1]
+			} else {
+				x = backward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			backward[i] = x
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !oddDelta:
+				j := offset + delta - k
+				if j >= 0 && j < len(forward) && forward[j] != -1 {
+					fx := forward[j]
+					fy := fx - (j - offset)
+					if fx >= n-x {
+						return fx, fy
+					}
+				}
+			}
+		}
+	}
+	return -1, -1
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	testingHelper "github.com/mahiro72/monkey-lang/testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			name:     "success: 同じ内容",
+			old:      "a\nb\n",
+			new:      "a\nb\n",
+			expected: "",
+		},
+		{
+			name:     "success: 行の変更",
+			old:      "a\nb\nc\n",
+			new:      "a\nB\nc\n",
+			expected: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:     "success: 行の追加と削除",
+			old:      "a\nb\n",
+			new:      "b\nc\n",
+			expected: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n-a\n b\n+c\n",
+		},
+		{
+			name:     "success: 空のファイルからの追加",
+			old:      "",
+			new:      "a\n",
+			expected: "--- old\n+++ new\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name:     "success: 末尾の改行の有無",
+			old:      "a\nb",
+			new:      "a\nb\n",
+			expected: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name:     "success: 離れた変更は別のハンクにする",
+			old:      "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			new:      "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			expected: "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+		{
+			name:     "success: 近い変更は同じハンクにまとめる",
+			old:      "1\n2\n3\n4\n5\n6\n7\n8\n",
+			new:      "one\n2\n3\n4\n5\n6\n7\neight\n",
+			expected: "--- old\n+++ new\n@@ -1,8 +1,8 @@\n-1\n+one\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+eight\n",
+		},
+		{
+			name:     "success: 連続した変更は削除を先に並べる",
+			old:      "a\nx\ny\nb\n",
+			new:      "a\ny\nz\nw\nb\n",
+			expected: "--- old\n+++ new\n@@ -1,4 +1,5 @@\n a\n-x\n y\n+z\n+w\n b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			unifiedDiff(&out, "old", "new", tt.old, tt.new)
+			testingHelper.AssertEqual(t, tt.expected, out.String())
+		})
+	}
+}
+
+// 行数の積に比例するメモリを使わずに、大きなファイルの差分を求められる
+func TestUnifiedDiffLargeFile(t *testing.T) {
+	const n = 200000
+	oldLines := make([]string, n)
+	for i := range oldLines {
+		oldLines[i] = fmt.Sprint(i)
+	}
+	newLines := append([]string{}, oldLines...)
+	newLines[100] = "changed"
+	newLines = append(newLines[:150000], newLines[150001:]...)
+
+	var out bytes.Buffer
+	unifiedDiff(&out, "old", "new", strings.Join(oldLines, "\n")+"\n", strings.Join(newLines, "\n")+"\n")
+	testingHelper.AssertEqual(t, "--- old\n+++ new\n"+
+		"@@ -98,7 +98,7 @@\n 97\n 98\n 99\n-100\n+changed\n 101\n 102\n 103\n"+
+		"@@ -149998,7 +149998,6 @@\n 149997\n 149998\n 149999\n-150000\n 150001\n 150002\n 150003\n",
+		out.String())
+}
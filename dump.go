@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mahiro72/monkey-lang/ast"
+	"github.com/mahiro72/monkey-lang/lexer"
+	"github.com/mahiro72/monkey-lang/parser"
+)
+
+// monkey ast [file]
+// ファイルを構文解析し、構文木をJSONとして出力する。ファイルを指定しない場合は標準入力を読み込む
+func runAST(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) > 1 {
+		fmt.Fprintln(stderr, "usage: monkey ast [file]")
+		return 2
+	}
+
+	filename := "<stdin>"
+	var src []byte
+	var err error
+	if len(args) == 1 {
+		filename = args[0]
+		src, err = os.ReadFile(filename)
+	} else {
+		src, err = io.ReadAll(stdin)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	p := parser.New(lexer.NewWithFilename(filename, string(src)))
+	program := p.ParseProgram()
+	if err := p.ParseErrors().Err(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	data, err := ast.Marshal(program)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	out.WriteByte('\n')
+	stdout.Write(out.Bytes())
+	return 0
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mahiro72/monkey-lang/ast"
+	"github.com/mahiro72/monkey-lang/lexer"
+	"github.com/mahiro72/monkey-lang/parser"
+	testingHelper "github.com/mahiro72/monkey-lang/testing"
+)
+
+func TestRunAST(t *testing.T) {
+	input := "let x = 1;\nputs(x)"
+
+	var stdout, stderr bytes.Buffer
+	status := runAST([]string{}, strings.NewReader(input), &stdout, &stderr)
+	testingHelper.AssertEqual(t, 0, status)
+	testingHelper.AssertEqual(t, "", stderr.String())
+
+	node, err := ast.Unmarshal(stdout.Bytes())
+	testingHelper.AssertEqual(t, nil, err)
+	expected := parser.New(lexer.NewWithFilename("<stdin>", input)).ParseProgram()
+	testingHelper.AssertEqual(t, ast.Node(expected), node)
+}
+
+func TestRunASTErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		stdin          string
+		expectedStatus int
+		expectedStderr string
+	}{
+		{
+			name:           "failure: 構文エラー",
+			args:           []string{},
+			stdin:          "let = 1;",
+			expectedStatus: 1,
+			expectedStderr: "<stdin>:1:5: expected next token to be IDENT, got = instead\n",
+		},
+		{
+			name:           "failure: 複数のファイル",
+			args:           []string{"a.mk", "b.mk"},
+			expectedStatus: 2,
+			expectedStderr: "usage: monkey ast [file]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			status := runAST(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+
+			testingHelper.AssertEqual(t, tt.expectedStatus, status)
+			testingHelper.AssertEqual(t, "", stdout.String())
+			testingHelper.AssertEqual(t, tt.expectedStderr, stderr.String())
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package evaluator
+
+import (
+	"math"
+
+	"github.com/mahiro72/monkey-lang/object"
+)
+
+// 整数の四則演算と剰余を行う。0除算は常にエラーとし、オーバーフローはOptions.CheckedArithmeticが有効な場合のみエラーとする
+func evalIntegerArithmetic(operator string, left, right int64, st *state) object.Object {
+	var result int64
+	var overflow bool
+
+	switch operator {
+	case "+":
+		result = left + right
+		overflow = (left^result)&(right^result) < 0 // 同符号同士の加算で符号が変わった
+	case "-":
+		result = left - right
+		overflow = (left^right)&(left^result) < 0 // 異符号同士の減算で符号が変わった
+	case "*":
+		result = left * right
+		overflow = left != 0 && (result/left != right || left == -1 && right == math.MinInt64)
+	case "/":
+		if right == 0 {
+			return newError("division by zero: %d / %d", left, right)
+		}
+		result = left / right
+		overflow = left == math.MinInt64 && right == -1
+	case "%":
+		if right == 0 {
+			return newError("modulo by zero: %d %% %d", left, right)
+		}
+		result = left % right // 結果の符号は左辺と同じ (math.MinInt64 % -1 は0)
+	default:
+		return newError("unknown operator: %s %s %s", object.INTEGER_OBJ, operator, object.INTEGER_OBJ)
+	}
+
+	if st.checkedArithmetic() && overflow {
+		return newError("integer overflow: %d %s %d", left, operator, right)
+	}
+	return &object.Integer{Value: result}
+}
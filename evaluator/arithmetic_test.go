@@ -0,0 +1,103 @@
+package evaluator_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mahiro72/monkey-lang/evaluator"
+	"github.com/mahiro72/monkey-lang/lexer"
+	"github.com/mahiro72/monkey-lang/object"
+	"github.com/mahiro72/monkey-lang/parser"
+	testingHelper "github.com/mahiro72/monkey-lang/testing"
+)
+
+func TestIntegerArithmetic(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		checked     bool
+		expectedObj object.Object
+	}{
+		{
+			name:        "failure: 0除算",
+			input:       `1 / 0`,
+			expectedObj: &object.Error{Message: "division by zero: 1 / 0"},
+		},
+		{
+			name:        "failure: 0除算 (checked)",
+			input:       `let zero = 0; 10 / zero`,
+			checked:     true,
+			expectedObj: &object.Error{Message: "division by zero: 10 / 0"},
+		},
+		{
+			name:        "failure: 0による剰余",
+			input:       `5 % 0`,
+			expectedObj: &object.Error{Message: "modulo by zero: 5 % 0"},
+		},
+		{
+			name:        "success: 最小値と-1の剰余 (checked)",
+			input:       `(-9223372036854775807 - 1) % -1`,
+			checked:     true,
+			expectedObj: &object.Integer{Value: 0},
+		},
+		{
+			name:        "success: 加算のオーバーフローはラップアラウンドする",
+			input:       `9223372036854775807 + 1`,
+			expectedObj: &object.Integer{Value: -9223372036854775808},
+		},
+		{
+			name:        "failure: 加算のオーバーフロー (checked)",
+			input:       `9223372036854775807 + 1`,
+			checked:     true,
+			expectedObj: &object.Error{Message: "integer overflow: 9223372036854775807 + 1"},
+		},
+		{
+			name:        "failure: 減算のオーバーフロー (checked)",
+			input:       `-9223372036854775807 - 2`,
+			checked:     true,
+			expectedObj: &object.Error{Message: "integer overflow: -9223372036854775807 - 2"},
+		},
+		{
+			name:        "failure: 乗算のオーバーフロー (checked)",
+			input:       `4611686018427387904 * 2`,
+			checked:     true,
+			expectedObj: &object.Error{Message: "integer overflow: 4611686018427387904 * 2"},
+		},
+		{
+			name:        "failure: 最小値と-1の乗算 (checked)",
+			input:       `-1 * (-9223372036854775807 - 1)`,
+			checked:     true,
+			expectedObj: &object.Error{Message: "integer overflow: -1 * -9223372036854775808"},
+		},
+		{
+			name:        "failure: 最小値を-1で除算 (checked)",
+			input:       `(-9223372036854775807 - 1) / -1`,
+			checked:     true,
+			expectedObj: &object.Error{Message: "integer overflow: -9223372036854775808 / -1"},
+		},
+		{
+			name:        "failure: 最小値の符号反転 (checked)",
+			input:       `-(-9223372036854775807 - 1)`,
+			checked:     true,
+			expectedObj: &object.Error{Message: "integer overflow: -(-9223372036854775808)"},
+		},
+		{
+			name:        "success: オーバーフローしない演算 (checked)",
+			input:       `(9223372036854775807 - 1) + 1 - (-9223372036854775807 + 1) * -1`,
+			checked:     true,
+			expectedObj: &object.Integer{Value: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+			program := p.ParseProgram()
+			env := object.NewEnvironment()
+			opts := evaluator.Options{CheckedArithmetic: tt.checked}
+			obj := evaluator.EvalContext(context.Background(), program, env, opts)
+
+			testingHelper.AssertEqual(t, tt.expectedObj, obj)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/mahiro72/monkey-lang/ast"
+	"github.com/mahiro72/monkey-lang/object"
+)
+
+// 代入式を評価し、代入した値を返す
+// letと異なり新しい束縛は作らず、既存の束縛や配列の要素、ハッシュの値を書き換える
+func evalAssignExpression(node *ast.AssignExpression, env *object.Environment, st *state) object.Object {
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		return evalIdentifierAssignment(node, target, env, st)
+	case *ast.IndexExpression:
+		return evalIndexAssignment(node, target, env, st)
+	default:
+		return newError("cannot assign to %s", node.Target.String())
+	}
+}
+
+func evalIdentifierAssignment(node *ast.AssignExpression, target *ast.Identifier, env *object.Environment, st *state) object.Object {
+	var current object.Object
+	if node.Operator != "=" {
+		var ok bool
+		if current, ok = env.Get(target.Value); !ok {
+			return newError("identifier not found: " + target.Value)
+		}
+	}
+
+	val := assignedValue(node, current, env, st)
+	if isAbrupt(val) {
+		return val
+	}
+
+	if _, ok := env.Assign(target.Value, val); !ok {
+		return newError("assignment to undeclared variable: %s", target.Value)
+	}
+	return val
+}
+
+// 配列の要素 (負のインデックスは末尾から数える) やハッシュの値を書き換える
+// 配列の範囲外への代入はエラーとし、ハッシュには新しいキーを追加できる
+func evalIndexAssignment(node *ast.AssignExpression, target *ast.IndexExpression, env *object.Environment, st *state) object.Object {
+	left := eval(target.Left, env, st)
+	if isAbrupt(left) {
+		return left
+	}
+	index := eval(target.Index, env, st)
+	if isAbrupt(index) {
+		return index
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		idx, ok := index.(*object.Integer)
+		if !ok {
+			return newError("array index must be INTEGER, got %s", index.Type())
+		}
+		i := idx.Value
+		length := int64(len(left.Elements))
+		if i < 0 {
+			i += length
+		}
+		if i < 0 || i >= length {
+			return newError("array index out of range: %d (length %d)", idx.Value, length)
+		}
+
+		val := assignedValue(node, left.Elements[i], env, st)
+		if isAbrupt(val) {
+			return val
+		}
+		left.Elements[i] = val
+		return val
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+
+		var current object.Object
+		if node.Operator != "=" {
+			pair, ok := left.Pairs[key.HashKey()]
+			if !ok {
+				return newError("key not found: %s", index.Inspect())
+			}
+			current = pair.Value
+		}
+
+		val := assignedValue(node, current, env, st)
+		if isAbrupt(val) {
+			return val
+		}
+		left.Pairs[key.HashKey()] = object.HashPair{Key: index, Value: val}
+		return val
+	default:
+		return newError("index assignment not supported: %s", left.Type())
+	}
+}
+
+// 代入する値を評価する。複合代入 (+= など) の場合はcurrentと右辺を演算した結果を返す
+func assignedValue(node *ast.AssignExpression, current object.Object, env *object.Environment, st *state) object.Object {
+	val := eval(node.Value, env, st)
+	if isAbrupt(val) || node.Operator == "=" {
+		return val
+	}
+	operator := strings.TrimSuffix(node.Operator, "=")
+	return evalInfixExpression(operator, current, val, env, st)
+}
@@ -0,0 +1,124 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/mahiro72/monkey-lang/evaluator"
+	"github.com/mahiro72/monkey-lang/lexer"
+	"github.com/mahiro72/monkey-lang/object"
+	"github.com/mahiro72/monkey-lang/parser"
+	testingHelper "github.com/mahiro72/monkey-lang/testing"
+)
+
+func TestEvalAssignExpression(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedObj object.Object
+	}{
+		{
+			name:        "success: 代入式は代入した値を返す",
+			input:       `let x = 1; x = 5`,
+			expectedObj: &object.Integer{Value: 5},
+		},
+		{
+			name:        "success: 連続した代入",
+			input:       `let a = 0; let b = 0; a = b = 3; a + b`,
+			expectedObj: &object.Integer{Value: 6},
+		},
+		{
+			name:        "success: 複合代入",
+			input:       `let x = 10; x += 5; x -= 3; x *= 2; x /= 4; x`,
+			expectedObj: &object.Integer{Value: 6},
+		},
+		{
+			name:        "success: 文字列の複合代入",
+			input:       `let s = "mon"; s += "key"; s`,
+			expectedObj: &object.String{Value: "monkey"},
+		},
+		{
+			name:        "success: 関数の中から外側の束縛を書き換える",
+			input:       `let count = 0; let inc = fn() { count += 1 }; inc(); inc(); count`,
+			expectedObj: &object.Integer{Value: 2},
+		},
+		{
+			name:        "success: クロージャの状態を更新する",
+			input:       `let counter = fn() { let n = 0; fn() { n = n + 1 } }; let c = counter(); c(); c(); c()`,
+			expectedObj: &object.Integer{Value: 3},
+		},
+		{
+			name:        "success: 内側で宣言した同名の変数は外側を書き換えない",
+			input:       `let x = 1; let f = fn() { let x = 2; x = 3; }; f(); x`,
+			expectedObj: &object.Integer{Value: 1},
+		},
+		{
+			name:        "success: ループの変数の更新",
+			input:       `let sum = 0; for (let i = 0; i < 5; i += 1) { sum += i; } sum`,
+			expectedObj: &object.Integer{Value: 10},
+		},
+		{
+			name:        "success: 配列の要素への代入",
+			input:       `let arr = [1, 2, 3]; arr[0] = 10; arr[-1] *= 5; arr`,
+			expectedObj: &object.Array{Elements: []object.Object{&object.Integer{Value: 10}, &object.Integer{Value: 2}, &object.Integer{Value: 15}}},
+		},
+		{
+			name:        "success: ハッシュへの代入とキーの追加",
+			input:       `let h = {"a": 1}; h["a"] += 1; h["b"] = 3; [h["a"], h["b"], len(h)]`,
+			expectedObj: &object.Array{Elements: []object.Object{&object.Integer{Value: 2}, &object.Integer{Value: 3}, &object.Integer{Value: 2}}},
+		},
+		{
+			name:        "success: 入れ子の添字アクセスへの代入",
+			input:       `let m = [[0, 0], [0, 0]]; m[1][0] = 7; m[1]`,
+			expectedObj: &object.Array{Elements: []object.Object{&object.Integer{Value: 7}, &object.Integer{Value: 0}}},
+		},
+		{
+			name:        "failure: 宣言していない変数への代入",
+			input:       `y = 1`,
+			expectedObj: &object.Error{Message: "assignment to undeclared variable: y"},
+		},
+		{
+			name:        "failure: 宣言していない変数への複合代入",
+			input:       `y += 1`,
+			expectedObj: &object.Error{Message: "identifier not found: y"},
+		},
+		{
+			name:        "failure: 複合代入の型の不一致",
+			input:       `let x = 1; x += "a"`,
+			expectedObj: &object.Error{Message: "type mismatch: INTEGER + STRING"},
+		},
+		{
+			name:        "failure: 範囲外の添字への代入",
+			input:       `let arr = [1]; arr[1] = 2`,
+			expectedObj: &object.Error{Message: "array index out of range: 1 (length 1)"},
+		},
+		{
+			name:        "failure: 存在しないキーへの複合代入",
+			input:       `let h = {}; h["a"] += 1`,
+			expectedObj: &object.Error{Message: "key not found: a"},
+		},
+		{
+			name:        "failure: 添字での代入ができない値",
+			input:       `let s = "abc"; s[0] = "x"`,
+			expectedObj: &object.Error{Message: "index assignment not supported: STRING"},
+		},
+		{
+			name:        "failure: ハッシュのキーにできない値",
+			input:       `let h = {}; h[[1]] = 1`,
+			expectedObj: &object.Error{Message: "unusable as hash key: ARRAY"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+			program := p.ParseProgram()
+			testingHelper.AssertEqual(t, []string{}, p.Errors())
+
+			env := object.NewEnvironment()
+			obj := evaluator.Eval(program, env)
+
+			testingHelper.AssertEqual(t, tt.expectedObj, obj)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package evaluator
+
+import (
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"github.com/mahiro72/monkey-lang/object"
+)
+
+var builtins = map[string]*object.Builtin{
+	"len":   {Name: "len", Fn: builtinLen},
+	"puts":  {Name: "puts", Fn: builtinPuts(os.Stdout)},
+	"first": {Name: "first", Fn: builtinFirst},
+	"last":  {Name: "last", Fn: builtinLast},
+	"rest":  {Name: "rest", Fn: builtinRest},
+	"push":  {Name: "push", Fn: builtinPush},
+	"type":  {Name: "type", Fn: builtinType},
+}
+
+// 文字列は文字数、配列は要素数、ハッシュはペア数を返す
+func builtinLen(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArgumentsError("len", 1, len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.String:
+		return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+	case *object.Array:
+		return &object.Integer{Value: int64(len(arg.Elements))}
+	case *object.Hash:
+		return &object.Integer{Value: int64(len(arg.Pairs))}
+	default:
+		return newError("argument to `len` not supported, got %s", args[0].Type())
+	}
+}
+
+// 引数を1行ずつoutに書き込むputsを返す
+func builtinPuts(out io.Writer) object.BuiltinFunction {
+	return func(args ...object.Object) object.Object {
+		for _, arg := range args {
+			io.WriteString(out, arg.Inspect())
+			io.WriteString(out, "\n")
+		}
+		return NULL
+	}
+}
+
+// putsの出力先だけをoutに差し替えた組み込み関数の表を返す (評価ごとに作るので共有の状態は変更しない)
+func builtinsWithOutput(out io.Writer) map[string]*object.Builtin {
+	table := make(map[string]*object.Builtin, len(builtins))
+	for name, builtin := range builtins {
+		table[name] = builtin
+	}
+	table["puts"] = &object.Builtin{Name: "puts", Fn: builtinPuts(out)}
+	return table
+}
+
+func builtinFirst(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArgumentsError("first", 1, len(args))
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("argument to `first` must be ARRAY, got %s", args[0].Type())
+	}
+
+	if len(arr.Elements) > 0 {
+		return arr.Elements[0]
+	}
+	return NULL
+}
+
+func builtinLast(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArgumentsError("last", 1, len(args))
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("argument to `last` must be ARRAY, got %s", args[0].Type())
+	}
+
+	length := len(arr.Elements)
+	if length > 0 {
+		return arr.Elements[length-1]
+	}
+	return NULL
+}
+
+// 先頭以外の要素を持つ新しい配列を返す
+func builtinRest(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArgumentsError("rest", 1, len(args))
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("argument to `rest` must be ARRAY, got %s", args[0].Type())
+	}
+
+	length := len(arr.Elements)
+	if length > 0 {
+		newElements := make([]object.Object, length-1)
+		copy(newElements, arr.Elements[1:length])
+		return &object.Array{Elements: newElements}
+	}
+	return NULL
+}
+
+// 末尾に要素を追加した新しい配列を返す (元の配列は変更しない)
+func builtinPush(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return wrongNumberOfArgumentsError("push", 2, len(args))
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("argument to `push` must be ARRAY, got %s", args[0].Type())
+	}
+
+	length := len(arr.Elements)
+	newElements := make([]object.Object, length+1)
+	copy(newElements, arr.Elements)
+	newElements[length] = args[1]
+	return &object.Array{Elements: newElements}
+}
+
+// 値の型名を文字列で返す
+func builtinType(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArgumentsError("type", 1, len(args))
+	}
+	return &object.String{Value: string(args[0].Type())}
+}
+
+func wrongNumberOfArgumentsError(name string, want, got int) *object.Error {
+	return newError("wrong number of arguments to `%s`: want=%d, got=%d", name, want, got)
+}
@@ -0,0 +1,172 @@
+package evaluator_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mahiro72/monkey-lang/evaluator"
+	"github.com/mahiro72/monkey-lang/lexer"
+	"github.com/mahiro72/monkey-lang/object"
+	"github.com/mahiro72/monkey-lang/parser"
+	testingHelper "github.com/mahiro72/monkey-lang/testing"
+)
+
+func TestBuiltinFunctions(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedObj object.Object
+	}{
+		{
+			name:        "success: len(\"\")",
+			input:       `len("")`,
+			expectedObj: &object.Integer{Value: 0},
+		},
+		{
+			name:        "success: len(\"hello world\")",
+			input:       `len("hello world")`,
+			expectedObj: &object.Integer{Value: 11},
+		},
+		{
+			name:        "success: lenはマルチバイト文字を1文字と数える",
+			input:       `len("こんにちは")`,
+			expectedObj: &object.Integer{Value: 5},
+		},
+		{
+			name:        "success: len([1, 2, 3])",
+			input:       `len([1, 2, 3])`,
+			expectedObj: &object.Integer{Value: 3},
+		},
+		{
+			name:        "success: len({\"a\": 1})",
+			input:       `len({"a": 1})`,
+			expectedObj: &object.Integer{Value: 1},
+		},
+		{
+			name:        "failure: len(1)",
+			input:       `len(1)`,
+			expectedObj: &object.Error{Message: "argument to `len` not supported, got INTEGER"},
+		},
+		{
+			name:        "failure: len(\"one\", \"two\")",
+			input:       `len("one", "two")`,
+			expectedObj: &object.Error{Message: "wrong number of arguments to `len`: want=1, got=2"},
+		},
+		{
+			name:        "success: first([1, 2, 3])",
+			input:       `first([1, 2, 3])`,
+			expectedObj: &object.Integer{Value: 1},
+		},
+		{
+			name:        "success: first([])",
+			input:       `first([])`,
+			expectedObj: &object.Null{},
+		},
+		{
+			name:        "failure: first(1)",
+			input:       `first(1)`,
+			expectedObj: &object.Error{Message: "argument to `first` must be ARRAY, got INTEGER"},
+		},
+		{
+			name:        "success: last([1, 2, 3])",
+			input:       `last([1, 2, 3])`,
+			expectedObj: &object.Integer{Value: 3},
+		},
+		{
+			name:        "success: last([])",
+			input:       `last([])`,
+			expectedObj: &object.Null{},
+		},
+		{
+			name:        "failure: last()",
+			input:       `last()`,
+			expectedObj: &object.Error{Message: "wrong number of arguments to `last`: want=1, got=0"},
+		},
+		{
+			name:  "success: rest([1, 2, 3])",
+			input: `rest([1, 2, 3])`,
+			expectedObj: &object.Array{Elements: []object.Object{
+				&object.Integer{Value: 2},
+				&object.Integer{Value: 3},
+			}},
+		},
+		{
+			name:        "success: rest([])",
+			input:       `rest([])`,
+			expectedObj: &object.Null{},
+		},
+		{
+			name:  "success: pushは元の配列を変更しない",
+			input: `let a = [1]; let b = push(a, 2); [a, b]`,
+			expectedObj: &object.Array{Elements: []object.Object{
+				&object.Array{Elements: []object.Object{&object.Integer{Value: 1}}},
+				&object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.Integer{Value: 2}}},
+			}},
+		},
+		{
+			name:        "failure: push(1, 1)",
+			input:       `push(1, 1)`,
+			expectedObj: &object.Error{Message: "argument to `push` must be ARRAY, got INTEGER"},
+		},
+		{
+			name:        "success: type(\"a\")",
+			input:       `type("a")`,
+			expectedObj: &object.String{Value: "STRING"},
+		},
+		{
+			name:        "success: type(len)",
+			input:       `type(len)`,
+			expectedObj: &object.String{Value: "BUILTIN"},
+		},
+		{
+			name:        "success: 同名の変数は組み込み関数より優先",
+			input:       `let len = fn(x) { 42 }; len("a")`,
+			expectedObj: &object.Integer{Value: 42},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+			program := p.ParseProgram()
+			env := object.NewEnvironment()
+			obj := evaluator.Eval(program, env)
+
+			testingHelper.AssertEqual(t, tt.expectedObj, obj)
+		})
+	}
+}
+
+func TestBuiltinPuts(t *testing.T) {
+	var out bytes.Buffer
+	l := lexer.New(`puts("hello", 1, [true]); puts()`)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	obj := evaluator.EvalContext(context.Background(), program, object.NewEnvironment(), evaluator.Options{Output: &out})
+
+	testingHelper.AssertEqual(t, &object.Null{}, obj)
+	testingHelper.AssertEqual(t, "hello\n1\n[true]\n", out.String())
+}
+
+// 並行に評価しても、putsはそれぞれの評価の出力先に書き込む
+func TestBuiltinPutsConcurrent(t *testing.T) {
+	outs := make([]bytes.Buffer, 8)
+	var wg sync.WaitGroup
+	for i := range outs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			program := parser.New(lexer.New(fmt.Sprintf(`for (let n = 0; n < 100; n += 1) { puts(%d) }`, i))).ParseProgram()
+			evaluator.EvalContext(context.Background(), program, object.NewEnvironment(), evaluator.Options{Output: &outs[i]})
+		}()
+	}
+	wg.Wait()
+
+	for i := range outs {
+		testingHelper.AssertEqual(t, strings.Repeat(fmt.Sprintf("%d\n", i), 100), outs[i].String())
+	}
+}
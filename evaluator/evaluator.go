@@ -2,6 +2,7 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/mahiro72/monkey-lang/ast"
 	"github.com/mahiro72/monkey-lang/object"
@@ -11,102 +12,170 @@ var (
 	NULL  = &object.Null{}
 	TRUE  = &object.Boolean{Value: true}
 	FALSE = &object.Boolean{Value: false}
+
+	BREAK    = &object.Break{}
+	CONTINUE = &object.Continue{}
 )
 
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	return eval(node, env, nil)
+}
+
+// stには評価全体で共有する制限の状態を渡す (nilの場合は制限なし)
+func eval(node ast.Node, env *object.Environment, st *state) object.Object {
+	defer annotatePanic(node)
+
+	if err := st.step(); err != nil {
+		return err
+	}
+
 	switch node := node.(type) {
 	// 文
 	case *ast.Program:
-		return evalProgram(node.Statements, env)
+		return evalProgram(node.Statements, env, st)
 	case *ast.ExpressionStatement:
-		return Eval(node.Expression, env)
+		return eval(node.Expression, env, st)
 	case *ast.BlockStatement:
-		return evalBlockStatements(node, env)
+		return evalBlockStatements(node, env, st)
 	case *ast.ReturnStatement:
-		val := Eval(node.ReturnValue, env)
-		if isError(val) {
+		val := eval(node.ReturnValue, env, st)
+		if isAbrupt(val) {
 			return val
 		}
 		return &object.ReturnValue{Value: val}
 	case *ast.LetStatement:
-		val := Eval(node.Value, env)
-		if isError(val) {
+		val := eval(node.Value, env, st)
+		if isAbrupt(val) {
 			return val
 		}
 		env.Set(node.Name.Value, val)
+	case *ast.WhileStatement:
+		return evalWhileStatement(node, env, st)
+	case *ast.ForStatement:
+		return evalForStatement(node, env, st)
+	case *ast.ForInStatement:
+		return evalForInStatement(node, env, st)
+	case *ast.BreakStatement:
+		return BREAK
+	case *ast.ContinueStatement:
+		return CONTINUE
 
 	// 式
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+	case *ast.StringLiteral:
+		return &object.String{Value: node.Value}
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 	case *ast.Identifier:
-		return evalIdentifier(node, env)
+		return evalIdentifier(node, env, st)
 	case *ast.PrefixExpression:
-		right := Eval(node.Right, env)
-		if isError(right) {
+		right := eval(node.Right, env, st)
+		if isAbrupt(right) {
 			return right
 		}
-		return evalPrefixExpression(node.Operator, right, env)
+		return evalPrefixExpression(node.Operator, right, env, st)
 	case *ast.InfixExpression:
-		left := Eval(node.Left, env)
-		if isError(left) {
+		if node.Operator == "&&" || node.Operator == "||" {
+			return evalLogicalExpression(node, env, st)
+		}
+		if node.Operator == "??" {
+			return evalNullishExpression(node, env, st)
+		}
+		left := eval(node.Left, env, st)
+		if isAbrupt(left) {
 			return left
 		}
-		right := Eval(node.Right, env)
-		if isError(right) {
+		right := eval(node.Right, env, st)
+		if isAbrupt(right) {
 			return right
 		}
-		return evalInfixExpression(node.Operator, left, right, env)
+		return evalInfixExpression(node.Operator, left, right, env, st)
 	case *ast.IfExpression:
-		return evalIfExpression(node, env)
+		return evalIfExpression(node, env, st)
+	case *ast.ConditionalExpression:
+		return evalConditionalExpression(node, env, st)
+	case *ast.MacroLiteral:
+		return newError("macro literal must be bound by a top-level let statement")
 	case *ast.FunctionLiteral:
 		params := node.Parameters
 		body := node.Body
-		return &object.Function{Parameters: params, Body: body, Env: env}
+		return &object.Function{Parameters: params, Body: body, Env: env, Name: node.Name}
 	case *ast.CallExpression:
-		function := Eval(node.Function, env)
-		if isError(function) {
+		if isQuoteCall(node, env) {
+			return evalQuoteCall(node, env, st)
+		}
+		function := eval(node.Function, env, st)
+		if isAbrupt(function) {
 			return function
 		}
-		args := evalExpressions(node.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
+		args := evalExpressions(node.Arguments, env, st)
+		if len(args) == 1 && isAbrupt(args[0]) {
 			return args[0]
 		}
-		return applyFunction(function, args)
+		return applyFunction(function, args, st)
+	case *ast.ArrayLiteral:
+		elements := evalExpressions(node.Elements, env, st)
+		if len(elements) == 1 && isAbrupt(elements[0]) {
+			return elements[0]
+		}
+		return &object.Array{Elements: elements}
+	case *ast.IndexExpression:
+		left := eval(node.Left, env, st)
+		if isAbrupt(left) {
+			return left
+		}
+		index := eval(node.Index, env, st)
+		if isAbrupt(index) {
+			return index
+		}
+		return evalIndexExpression(left, index)
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env, st)
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, env, st)
 	}
 	return nil
 }
 
-func evalProgram(stmts []ast.Statement, env *object.Environment) object.Object {
+func evalProgram(stmts []ast.Statement, env *object.Environment, st *state) object.Object {
 	var result object.Object
 
 	for _, statement := range stmts {
-		result = Eval(statement, env)
+		result = eval(statement, env, st)
 
 		switch result := result.(type) {
 		case *object.ReturnValue:
 			return result.Value
 		case *object.Error:
 			return result
+		case *object.Break, *object.Continue:
+			return loopSignalError(result)
 		}
 	}
 	return result
 }
 
-func evalBlockStatements(block *ast.BlockStatement, env *object.Environment) object.Object {
+func evalBlockStatements(block *ast.BlockStatement, env *object.Environment, st *state) object.Object {
 	var result object.Object
 
 	for _, statement := range block.Statements {
-		result = Eval(statement, env)
+		result = eval(statement, env, st)
 
 		if result != nil {
 			rt := result.Type()
-			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ || rt == object.BREAK_OBJ || rt == object.CONTINUE_OBJ {
 				return result
 			}
 		}
 	}
+
+	// 空のブロックやlet文で終わるブロックも値としてはNULLを返す
+	if result == nil {
+		return NULL
+	}
 	return result
 }
 
@@ -117,20 +186,22 @@ func nativeBoolToBooleanObject(input bool) *object.Boolean {
 	return FALSE
 }
 
-func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
-	val, ok := env.Get(node.Value)
-	if !ok {
-		return newError("identifier not found: " + node.Value)
+func evalIdentifier(node *ast.Identifier, env *object.Environment, st *state) object.Object {
+	if val, ok := env.Get(node.Value); ok {
+		return val
 	}
-	return val
+	if builtin, ok := st.builtin(node.Value); ok {
+		return builtin
+	}
+	return newError("identifier not found: " + node.Value)
 }
 
-func evalPrefixExpression(operator string, right object.Object, env *object.Environment) object.Object {
+func evalPrefixExpression(operator string, right object.Object, env *object.Environment, st *state) object.Object {
 	switch operator {
 	case "!":
 		return evalBangOperatorExpression(right, env)
 	case "-":
-		return evalMinusPrefixOperatorExpression(right, env)
+		return evalMinusPrefixOperatorExpression(right, env, st)
 	default:
 		return NULL
 	}
@@ -149,19 +220,29 @@ func evalBangOperatorExpression(right object.Object, env *object.Environment) ob
 	}
 }
 
-func evalMinusPrefixOperatorExpression(right object.Object, env *object.Environment) object.Object {
+func evalMinusPrefixOperatorExpression(right object.Object, env *object.Environment, st *state) object.Object {
+	if f, ok := right.(*object.Float); ok {
+		return &object.Float{Value: -f.Value}
+	}
 	if right.Type() != object.INTEGER_OBJ {
 		return newError("unknown operator: -%s", right.Type())
 	}
 
 	value := right.(*object.Integer).Value
+	if st.checkedArithmetic() && value == math.MinInt64 {
+		return newError("integer overflow: -(%d)", value)
+	}
 	return &object.Integer{Value: -value}
 }
 
-func evalInfixExpression(operator string, left, right object.Object, env *object.Environment) object.Object {
+func evalInfixExpression(operator string, left, right object.Object, env *object.Environment, st *state) object.Object {
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
-		return evalIntegerInfixExpression(operator, left, right, env)
+		return evalIntegerInfixExpression(operator, left, right, env, st)
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(operator, left, right, env)
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return evalStringInfixExpression(operator, left, right, env)
 	case operator == "==":
 		return nativeBoolToBooleanObject(left == right) //left,rightのオブジェクト(TRUE,FALSE)が一致するかどうか
 	case operator == "!=":
@@ -173,23 +254,87 @@ func evalInfixExpression(operator string, left, right object.Object, env *object
 	}
 }
 
-func evalIntegerInfixExpression(operator string, left, right object.Object, env *object.Environment) object.Object {
+// &&と||を評価する。左辺だけで結果が決まる場合は右辺を評価しない
+func evalLogicalExpression(node *ast.InfixExpression, env *object.Environment, st *state) object.Object {
+	left := eval(node.Left, env, st)
+	if isAbrupt(left) {
+		return left
+	}
+	if node.Operator == "&&" && !isTruthy(left) {
+		return FALSE
+	}
+	if node.Operator == "||" && isTruthy(left) {
+		return TRUE
+	}
+
+	right := eval(node.Right, env, st)
+	if isAbrupt(right) {
+		return right
+	}
+	return nativeBoolToBooleanObject(isTruthy(right))
+}
+
+// 左辺がnullの場合のみ右辺を評価し、その値を返す。それ以外は左辺の値をそのまま返す
+func evalNullishExpression(node *ast.InfixExpression, env *object.Environment, st *state) object.Object {
+	left := eval(node.Left, env, st)
+	if isAbrupt(left) {
+		return left
+	}
+	if left != NULL {
+		return left
+	}
+	return eval(node.Right, env, st)
+}
+
+func evalIntegerInfixExpression(operator string, left, right object.Object, env *object.Environment, st *state) object.Object {
 	leftValue := left.(*object.Integer).Value
 	rightValue := right.(*object.Integer).Value
 
+	switch operator {
+	case "+", "-", "*", "/", "%":
+		return evalIntegerArithmetic(operator, leftValue, rightValue, st)
+	case "<":
+		return nativeBoolToBooleanObject(leftValue < rightValue)
+	case ">":
+		return nativeBoolToBooleanObject(leftValue > rightValue)
+	case "<=":
+		return nativeBoolToBooleanObject(leftValue <= rightValue)
+	case ">=":
+		return nativeBoolToBooleanObject(leftValue >= rightValue)
+	case "==":
+		return nativeBoolToBooleanObject(leftValue == rightValue)
+	case "!=":
+		return nativeBoolToBooleanObject(leftValue != rightValue)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// 少なくとも一方が浮動小数点数の場合、整数を浮動小数点数に変換して計算する
+func evalFloatInfixExpression(operator string, left, right object.Object, env *object.Environment) object.Object {
+	leftValue := toFloat(left)
+	rightValue := toFloat(right)
+
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftValue + rightValue}
+		return &object.Float{Value: leftValue + rightValue}
 	case "-":
-		return &object.Integer{Value: leftValue - rightValue}
+		return &object.Float{Value: leftValue - rightValue}
 	case "*":
-		return &object.Integer{Value: leftValue * rightValue}
+		return &object.Float{Value: leftValue * rightValue}
 	case "/":
-		return &object.Integer{Value: leftValue / rightValue}
+		if rightValue == 0 {
+			return newError("division by zero: %s / %s", left.Inspect(), right.Inspect())
+		}
+		return &object.Float{Value: leftValue / rightValue}
 	case "<":
 		return nativeBoolToBooleanObject(leftValue < rightValue)
 	case ">":
 		return nativeBoolToBooleanObject(leftValue > rightValue)
+	case "<=":
+		return nativeBoolToBooleanObject(leftValue <= rightValue)
+	case ">=":
+		return nativeBoolToBooleanObject(leftValue >= rightValue)
 	case "==":
 		return nativeBoolToBooleanObject(leftValue == rightValue)
 	case "!=":
@@ -199,27 +344,138 @@ func evalIntegerInfixExpression(operator string, left, right object.Object, env
 	}
 }
 
-func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
-	condition := Eval(ie.Condition, env)
-	if isError(condition) {
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.Float:
+		return obj.Value
+	default:
+		return 0
+	}
+}
+
+func evalStringInfixExpression(operator string, left, right object.Object, env *object.Environment) object.Object {
+	leftValue := left.(*object.String).Value
+	rightValue := right.(*object.String).Value
+
+	switch operator {
+	case "+":
+		return &object.String{Value: leftValue + rightValue}
+	case "==":
+		return nativeBoolToBooleanObject(leftValue == rightValue)
+	case "!=":
+		return nativeBoolToBooleanObject(leftValue != rightValue)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evalIfExpression(ie *ast.IfExpression, env *object.Environment, st *state) object.Object {
+	condition := eval(ie.Condition, env, st)
+	if isAbrupt(condition) {
 		return condition
 	}
 
 	if isTruthy(condition) {
-		return Eval(ie.Consequence, env)
+		return eval(ie.Consequence, env, st)
 	} else if ie.Alternative != nil {
-		return Eval(ie.Alternative, env)
+		return eval(ie.Alternative, env, st)
 	} else {
 		return NULL
 	}
 }
 
-func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
+// 条件に応じて選ばれた方の式だけを評価する
+func evalConditionalExpression(ce *ast.ConditionalExpression, env *object.Environment, st *state) object.Object {
+	condition := eval(ce.Condition, env, st)
+	if isAbrupt(condition) {
+		return condition
+	}
+	if isTruthy(condition) {
+		return eval(ce.Consequence, env, st)
+	}
+	return eval(ce.Alternative, env, st)
+}
+
+func evalIndexExpression(left, index object.Object) object.Object {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.ARRAY_OBJ:
+		return newError("array index must be INTEGER, got %s", index.Type())
+	case left.Type() == object.HASH_OBJ:
+		return evalHashIndexExpression(left, index)
+	default:
+		return newError("index operator not supported: %s", left.Type())
+	}
+}
+
+// 負のインデックスは末尾から数える。範囲外の場合はNULLを返す
+func evalArrayIndexExpression(array, index object.Object) object.Object {
+	arrayObject := array.(*object.Array)
+	idx := index.(*object.Integer).Value
+	length := int64(len(arrayObject.Elements))
+
+	if idx < 0 {
+		idx += length
+	}
+	if idx < 0 || idx >= length {
+		return NULL
+	}
+	return arrayObject.Elements[idx]
+}
+
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment, st *state) object.Object {
+	pairs := make(map[object.HashKey]object.HashPair)
+
+	for _, pair := range node.Pairs {
+		key := eval(pair.Key, env, st)
+		if isAbrupt(key) {
+			return key
+		}
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", key.Type())
+		}
+
+		value := eval(pair.Value, env, st)
+		if isAbrupt(value) {
+			return value
+		}
+
+		pairs[hashKey.HashKey()] = object.HashPair{Key: key, Value: value}
+	}
+	return &object.Hash{Pairs: pairs}
+}
+
+// キーが存在しない場合はNULLを返す
+func evalHashIndexExpression(hash, index object.Object) object.Object {
+	hashObject := hash.(*object.Hash)
+
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return newError("unusable as hash key: %s", index.Type())
+	}
+
+	pair, ok := hashObject.Pairs[key.HashKey()]
+	if !ok {
+		return NULL
+	}
+	return pair.Value
+}
+
+func evalExpressions(exps []ast.Expression, env *object.Environment, st *state) []object.Object {
 	var result []object.Object
 
 	for _, e := range exps {
-		evaluated := Eval(e, env)
-		if isError(evaluated) {
+		evaluated := eval(e, env, st)
+		if isAbrupt(evaluated) {
 			return []object.Object{evaluated}
 		}
 		result = append(result, evaluated)
@@ -228,15 +484,33 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 }
 
 // 関数を実行する
-func applyFunction(fn object.Object, args []object.Object) object.Object {
-	function, ok := fn.(*object.Function)
-	if !ok {
+func applyFunction(fn object.Object, args []object.Object, st *state) object.Object {
+	switch function := fn.(type) {
+	case *object.Function:
+		if len(args) != len(function.Parameters) {
+			return wrongNumberOfArgumentsError(functionName(function), len(function.Parameters), len(args))
+		}
+		if err := st.enterCall(function); err != nil {
+			return err
+		}
+		defer st.leaveCall()
+
+		extendedEnv := extendFunctionEnv(function, args)
+		evaluated := eval(function.Body, extendedEnv, st)
+		return unwrapReturnValue(evaluated)
+	case *object.Builtin:
+		return function.Fn(args...)
+	default:
 		return newError("not a function: %s", fn.Type())
 	}
+}
 
-	extendedEnv := extendFunctionEnv(function, args)
-	evaluated := Eval(function.Body, extendedEnv)
-	return unwrapReturnValue(evaluated)
+// エラーメッセージ用の関数名。無名関数は"fn"とする
+func functionName(fn *object.Function) string {
+	if fn.Name == "" {
+		return "fn"
+	}
+	return fn.Name
 }
 
 func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
@@ -250,8 +524,11 @@ func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Enviro
 }
 
 func unwrapReturnValue(obj object.Object) object.Object {
-	if returnValue, ok := obj.(*object.ReturnValue)	; ok {
-		return returnValue.Value // returnの効果が関数を跨いで評価されてしまうことを防ぐために、Valueのみを返す
+	switch obj := obj.(type) {
+	case *object.ReturnValue:
+		return obj.Value // returnの効果が関数を跨いで評価されてしまうことを防ぐために、Valueのみを返す
+	case *object.Break, *object.Continue:
+		return loopSignalError(obj) // break, continueも関数の外のループには届かない
 	}
 	return obj
 }
@@ -279,3 +556,13 @@ func isError(obj object.Object) bool {
 	}
 	return false
 }
+
+// エラーに加えて、if式のブロックなどから伝わるreturn, break, continueも、式の値として使わずにそのまま外側へ伝える
+// これらを受け取った式は残りの部分を評価せず、let文や代入も行わない
+func isAbrupt(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Error, *object.ReturnValue, *object.Break, *object.Continue:
+		return true
+	}
+	return false
+}
@@ -81,6 +81,51 @@ func TestEvalBooleanExpression(t *testing.T) {
 			input:       `(3 > 1) == true`,
 			expectedObj: &object.Boolean{Value: true},
 		},
+		{
+			name:        "success: 1 <= 1",
+			input:       `1 <= 1`,
+			expectedObj: &object.Boolean{Value: true},
+		},
+		{
+			name:        "success: 1 >= 2",
+			input:       `1 >= 2`,
+			expectedObj: &object.Boolean{Value: false},
+		},
+		{
+			name:        "success: 2.5 >= 2",
+			input:       `2.5 >= 2`,
+			expectedObj: &object.Boolean{Value: true},
+		},
+		{
+			name:        "success: true && false",
+			input:       `true && false`,
+			expectedObj: &object.Boolean{Value: false},
+		},
+		{
+			name:        "success: false || 1",
+			input:       `false || 1`,
+			expectedObj: &object.Boolean{Value: true},
+		},
+		{
+			name:        "success: 1 < 2 && 2 < 3 || false",
+			input:       `1 < 2 && 2 < 3 || false`,
+			expectedObj: &object.Boolean{Value: true},
+		},
+		{
+			name:        "success: &&は左辺が偽なら右辺を評価しない",
+			input:       `false && undefined`,
+			expectedObj: &object.Boolean{Value: false},
+		},
+		{
+			name:        "success: ||は左辺が真なら右辺を評価しない",
+			input:       `let called = fn() { 1 / 0 }; true || called()`,
+			expectedObj: &object.Boolean{Value: true},
+		},
+		{
+			name:        "failure: 右辺を評価した場合のエラーはそのまま返す",
+			input:       `true && undefined`,
+			expectedObj: &object.Error{Message: "identifier not found: undefined"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -121,6 +166,16 @@ func TestEvalIntegerInfixExpression(t *testing.T) {
 			input:       `5 / 5;`,
 			expectedObj: &object.Integer{Value: 1},
 		},
+		{
+			name:        "success: 7 % 3",
+			input:       `7 % 3;`,
+			expectedObj: &object.Integer{Value: 1},
+		},
+		{
+			name:        "success: -7 % 3",
+			input:       `-7 % 3;`,
+			expectedObj: &object.Integer{Value: -1},
+		},
 	}
 
 	for _, tt := range tests {
@@ -162,6 +217,26 @@ func TestEvalIfExpression(t *testing.T) {
 			input:       `if (5 < 10) { 10 } else { 20 };`,
 			expectedObj: &object.Integer{Value: 10},
 		},
+		{
+			name:        "success: else ifの2番目の分岐",
+			input:       `let x = 5; if (x < 0) { "負" } else if (x == 0) { "零" } else if (x < 10) { "小" } else { "大" }`,
+			expectedObj: &object.String{Value: "小"},
+		},
+		{
+			name:        "success: else ifの最後のelse",
+			input:       `let x = 50; if (x < 0) { "負" } else if (x < 10) { "小" } else { "大" }`,
+			expectedObj: &object.String{Value: "大"},
+		},
+		{
+			name:        "success: elseの無いelse ifでどれにも当てはまらない",
+			input:       `if (false) { 1 } else if (false) { 2 }`,
+			expectedObj: &object.Null{},
+		},
+		{
+			name:        "success: else ifの中のreturn",
+			input:       `let f = fn(x) { if (x == 1) { return "a" } else if (x == 2) { return "b" } "c" }; f(2) + f(3)`,
+			expectedObj: &object.String{Value: "bc"},
+		},
 		{
 			name: "success: ネストされたif",
 			input: `
@@ -281,3 +356,403 @@ func TestEvalLetStatement(t *testing.T) {
 		})
 	}
 }
+
+func TestEvalStringExpression(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedObj object.Object
+	}{
+		{
+			name:        "success: 文字列リテラル",
+			input:       `"Hello World!"`,
+			expectedObj: &object.String{Value: "Hello World!"},
+		},
+		{
+			name:        "success: 文字列の連結",
+			input:       `"Hello" + " " + "World!"`,
+			expectedObj: &object.String{Value: "Hello World!"},
+		},
+		{
+			name:        "success: 文字列の比較(==)",
+			input:       `"monkey" == "mon" + "key"`,
+			expectedObj: &object.Boolean{Value: true},
+		},
+		{
+			name:        "success: 文字列の比較(!=)",
+			input:       `"monkey" != "monkey"`,
+			expectedObj: &object.Boolean{Value: false},
+		},
+		{
+			name:        "failure: 文字列の減算",
+			input:       `"Hello" - "World"`,
+			expectedObj: &object.Error{Message: "unknown operator: STRING - STRING"},
+		},
+		{
+			name:        "failure: 文字列と数値の連結",
+			input:       `"Hello" + 1`,
+			expectedObj: &object.Error{Message: "type mismatch: STRING + INTEGER"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+			program := p.ParseProgram()
+			env := object.NewEnvironment()
+			obj := evaluator.Eval(program, env)
+
+			testingHelper.AssertEqual(t, tt.expectedObj, obj)
+		})
+	}
+}
+
+func TestEvalArrayExpression(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedObj object.Object
+	}{
+		{
+			name:  "success: 配列リテラル",
+			input: `[1, 2 * 2, 3 + 3]`,
+			expectedObj: &object.Array{Elements: []object.Object{
+				&object.Integer{Value: 1},
+				&object.Integer{Value: 4},
+				&object.Integer{Value: 6},
+			}},
+		},
+		{
+			name:        "success: 添字アクセス",
+			input:       `[1, 2, 3][0]`,
+			expectedObj: &object.Integer{Value: 1},
+		},
+		{
+			name:        "success: 変数への添字アクセス",
+			input:       `let i = 0; let arr = [1, 2, 3]; arr[i] + arr[i + 1] + arr[2]`,
+			expectedObj: &object.Integer{Value: 6},
+		},
+		{
+			name:        "success: 負の添字は末尾から数える",
+			input:       `[1, 2, 3][-1]`,
+			expectedObj: &object.Integer{Value: 3},
+		},
+		{
+			name:        "success: 範囲外の添字",
+			input:       `[1, 2, 3][3]`,
+			expectedObj: &object.Null{},
+		},
+		{
+			name:        "success: 範囲外の負の添字",
+			input:       `[1, 2, 3][-4]`,
+			expectedObj: &object.Null{},
+		},
+		{
+			name:        "failure: 整数以外の添字",
+			input:       `[1, 2, 3][true]`,
+			expectedObj: &object.Error{Message: "array index must be INTEGER, got BOOLEAN"},
+		},
+		{
+			name:        "failure: 配列以外への添字アクセス",
+			input:       `1[0]`,
+			expectedObj: &object.Error{Message: "index operator not supported: INTEGER"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+			program := p.ParseProgram()
+			env := object.NewEnvironment()
+			obj := evaluator.Eval(program, env)
+
+			testingHelper.AssertEqual(t, tt.expectedObj, obj)
+		})
+	}
+}
+
+func TestEvalHashExpression(t *testing.T) {
+	tests := []struct {
+		name                string
+		input               string
+		expectedInspect     string
+		expectedErrorString string
+	}{
+		{
+			name:            "success: ハッシュリテラル",
+			input:           `let two = "two"; {"one": 10 - 9, two: 1 + 1, "thr" + "ee": 6 / 2, 4: 4, true: 5, false: 6}`,
+			expectedInspect: "{4: 4, false: 6, one: 1, three: 3, true: 5, two: 2}",
+		},
+		{
+			name:            "success: 文字列キーでの参照",
+			input:           `let key = "foo"; {"foo": 5}[key]`,
+			expectedInspect: "5",
+		},
+		{
+			name:            "success: 存在しないキー",
+			input:           `{"foo": 5}["bar"]`,
+			expectedInspect: "null",
+		},
+		{
+			name:            "success: 整数キーでの参照",
+			input:           `{5: 5}[5]`,
+			expectedInspect: "5",
+		},
+		{
+			name:            "success: 真偽値キーでの参照",
+			input:           `{true: 5}[1 < 2]`,
+			expectedInspect: "5",
+		},
+		{
+			name:            "success: 同じキーは後の値で上書き",
+			input:           `{"a": 1, "a": 2}`,
+			expectedInspect: "{a: 2}",
+		},
+		{
+			name:                "failure: 関数をキーにしたハッシュリテラル",
+			input:               `{fn(x) { x }: "fn"}`,
+			expectedErrorString: "Error: unusable as hash key: FUNCTION",
+		},
+		{
+			name:                "failure: 関数のキーで参照",
+			input:               `{"name": "monkey"}[fn(x) { x }]`,
+			expectedErrorString: "Error: unusable as hash key: FUNCTION",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+			program := p.ParseProgram()
+			env := object.NewEnvironment()
+			obj := evaluator.Eval(program, env)
+
+			if tt.expectedErrorString != "" {
+				testingHelper.AssertEqual(t, tt.expectedErrorString, obj.Inspect())
+			} else {
+				testingHelper.AssertEqual(t, tt.expectedInspect, obj.Inspect())
+			}
+		})
+	}
+}
+
+func TestEvalFunctionApplication(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedObj object.Object
+	}{
+		{
+			name:        "success: 関数の呼び出し",
+			input:       `let add = fn(a, b) { a + b }; add(1, 2)`,
+			expectedObj: &object.Integer{Value: 3},
+		},
+		{
+			name:        "success: 無名関数の即時呼び出し",
+			input:       `fn(x) { x * 2 }(5)`,
+			expectedObj: &object.Integer{Value: 10},
+		},
+		{
+			name:        "success: クロージャ",
+			input:       `let adder = fn(x) { fn(y) { x + y } }; adder(2)(3)`,
+			expectedObj: &object.Integer{Value: 5},
+		},
+		{
+			name:        "failure: 引数が足りない",
+			input:       `let add = fn(a, b) { a + b }; add(1)`,
+			expectedObj: &object.Error{Message: "wrong number of arguments to `add`: want=2, got=1"},
+		},
+		{
+			name:        "failure: 引数が多すぎる",
+			input:       `let add = fn(a, b) { a + b }; add(1, 2, 3)`,
+			expectedObj: &object.Error{Message: "wrong number of arguments to `add`: want=2, got=3"},
+		},
+		{
+			name:        "failure: 無名関数の引数が足りない",
+			input:       `fn(a, b) { a }(1)`,
+			expectedObj: &object.Error{Message: "wrong number of arguments to `fn`: want=2, got=1"},
+		},
+		{
+			name:        "failure: 関数ではない値の呼び出し",
+			input:       `let x = 1; x()`,
+			expectedObj: &object.Error{Message: "not a function: INTEGER"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+			program := p.ParseProgram()
+			env := object.NewEnvironment()
+			obj := evaluator.Eval(program, env)
+
+			testingHelper.AssertEqual(t, tt.expectedObj, obj)
+		})
+	}
+}
+
+func TestEvalConditionalExpression(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedObj object.Object
+	}{
+		{
+			name:        "success: 条件が真",
+			input:       `1 < 2 ? "yes" : "no"`,
+			expectedObj: &object.String{Value: "yes"},
+		},
+		{
+			name:        "success: 条件が偽",
+			input:       `let x = -3; x > 0 ? x : -x`,
+			expectedObj: &object.Integer{Value: 3},
+		},
+		{
+			name:        "success: 連なった条件演算子",
+			input:       `let n = 0; n < 0 ? "負" : n == 0 ? "零" : "正"`,
+			expectedObj: &object.String{Value: "零"},
+		},
+		{
+			name:        "success: 選ばれなかった方は評価しない",
+			input:       `true ? 1 : undefined`,
+			expectedObj: &object.Integer{Value: 1},
+		},
+		{
+			name:        "success: nullでない左辺はそのまま",
+			input:       `0 ?? 1`,
+			expectedObj: &object.Integer{Value: 0},
+		},
+		{
+			name:        "success: falseもnullではない",
+			input:       `false ?? true`,
+			expectedObj: &object.Boolean{Value: false},
+		},
+		{
+			name:        "success: nullの場合は右辺",
+			input:       `let config = {"port": 8080}; config["host"] ?? "localhost"`,
+			expectedObj: &object.String{Value: "localhost"},
+		},
+		{
+			name:        "success: 左辺がnullでなければ右辺を評価しない",
+			input:       `"set" ?? undefined`,
+			expectedObj: &object.String{Value: "set"},
+		},
+		{
+			name:        "success: 連なったnull合体演算子",
+			input:       `let h = {}; h["a"] ?? h["b"] ?? "c"`,
+			expectedObj: &object.String{Value: "c"},
+		},
+		{
+			name:        "failure: 条件のエラー",
+			input:       `undefined ? 1 : 2`,
+			expectedObj: &object.Error{Message: "identifier not found: undefined"},
+		},
+		{
+			name:        "failure: 右辺のエラー",
+			input:       `[1][5] ?? undefined`,
+			expectedObj: &object.Error{Message: "identifier not found: undefined"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+			program := p.ParseProgram()
+			env := object.NewEnvironment()
+			obj := evaluator.Eval(program, env)
+
+			testingHelper.AssertEqual(t, tt.expectedObj, obj)
+		})
+	}
+}
+
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedObj object.Object
+	}{
+		{
+			name:        "success: 浮動小数点数",
+			input:       `3.14`,
+			expectedObj: &object.Float{Value: 3.14},
+		},
+		{
+			name:        "success: 符号反転",
+			input:       `-2.5`,
+			expectedObj: &object.Float{Value: -2.5},
+		},
+		{
+			name:        "success: 浮動小数点数同士の演算",
+			input:       `1.5 * 2.0 + 0.25 / 0.5 - 1e-1`,
+			expectedObj: &object.Float{Value: 3.4},
+		},
+		{
+			name:        "success: 整数は浮動小数点数に昇格する",
+			input:       `1 + 0.5`,
+			expectedObj: &object.Float{Value: 1.5},
+		},
+		{
+			name:        "success: 整数同士の除算は整数のまま",
+			input:       `7 / 2`,
+			expectedObj: &object.Integer{Value: 3},
+		},
+		{
+			name:        "success: 整数と浮動小数点数の除算",
+			input:       `7 / 2.0`,
+			expectedObj: &object.Float{Value: 3.5},
+		},
+		{
+			name:        "success: 整数と浮動小数点数の比較",
+			input:       `1 == 1.0`,
+			expectedObj: &object.Boolean{Value: true},
+		},
+		{
+			name:        "success: 浮動小数点数の大小比較",
+			input:       `(0.1 < 1) == (2.5 > 2)`,
+			expectedObj: &object.Boolean{Value: true},
+		},
+		{
+			name:        "failure: 0除算",
+			input:       `1.5 / 0`,
+			expectedObj: &object.Error{Message: "division by zero: 1.5 / 0"},
+		},
+		{
+			name:        "failure: 浮動小数点数と文字列",
+			input:       `1.5 + "a"`,
+			expectedObj: &object.Error{Message: "type mismatch: FLOAT + STRING"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+			program := p.ParseProgram()
+			env := object.NewEnvironment()
+			obj := evaluator.Eval(program, env)
+
+			testingHelper.AssertEqual(t, tt.expectedObj, obj)
+		})
+	}
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedInspect string
+	}{
+		{input: `1.5`, expectedInspect: "1.5"},
+		{input: `2.0`, expectedInspect: "2.0"},
+		{input: `1e21`, expectedInspect: "1e+21"},
+		{input: `-0.5 * 4`, expectedInspect: "-2.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			program := parser.New(lexer.New(tt.input)).ParseProgram()
+			obj := evaluator.Eval(program, object.NewEnvironment())
+
+			testingHelper.AssertEqual(t, tt.expectedInspect, obj.Inspect())
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package evaluator
+
+import (
+	"context"
+	"io"
+
+	"github.com/mahiro72/monkey-lang/ast"
+	"github.com/mahiro72/monkey-lang/object"
+)
+
+// SafeEvalが使う関数呼び出しのネストの上限
+// ホストのGoのスタック (既定で最大1GB) を使い切らない範囲で、通常の再帰には十分な深さにしている
+const DefaultMaxDepth = 10000
+
+// 評価に対する制限。0の項目は無制限として扱う
+// MaxDepthを0にすると、深い再帰でホストのスタックが溢れてプロセスごと停止する
+type Limits struct {
+	MaxSteps int // 評価するノード数の上限
+	MaxDepth int // 関数呼び出しのネストの上限
+}
+
+// 評価ごとの設定。ゼロ値は制限なしで、putsはos.Stdoutに出力し、整数演算はラップアラウンドする
+// 評価ごとに渡すので、複数のスクリプトを並行に評価しても互いに影響しない
+type Options struct {
+	Limits
+	Output io.Writer // putsの出力先 (nilの場合はos.Stdout)
+
+	// trueの場合、整数演算のオーバーフローをラップアラウンドさせずにエラーとして返す
+	CheckedArithmetic bool
+}
+
+// 1回の評価全体で共有する状態
+type state struct {
+	done     <-chan struct{}
+	ctx      context.Context
+	limits   Limits
+	checked  bool                       // Options.CheckedArithmetic
+	builtins map[string]*object.Builtin // putsの出力先を差し替えた組み込み関数 (nilの場合は既定のもの)
+
+	steps int
+	depth int
+	err   *object.Error // 一度制限を超えたら以降の評価はすべてこのエラーを返す
+}
+
+// ctxがキャンセルされるか、opts.Limitsを超えた時点で評価を打ち切るEval
+// 打ち切った場合はKindがTimeoutError, StepLimitError, StackOverflowErrorのobject.Errorを返す
+// 信頼できないスクリプトを評価することを想定し、SafeEvalと同様にpanicも内部エラーに変換する
+func EvalContext(ctx context.Context, node ast.Node, env *object.Environment, opts Options) object.Object {
+	return safeEval(node, env, newState(ctx, opts))
+}
+
+func newState(ctx context.Context, opts Options) *state {
+	st := &state{done: ctx.Done(), ctx: ctx, limits: opts.Limits, checked: opts.CheckedArithmetic}
+	if opts.Output != nil {
+		st.builtins = builtinsWithOutput(opts.Output)
+	}
+	return st
+}
+
+func (st *state) checkedArithmetic() bool {
+	return st != nil && st.checked
+}
+
+// 組み込み関数を名前で探す
+func (st *state) builtin(name string) (*object.Builtin, bool) {
+	if st == nil || st.builtins == nil {
+		builtin, ok := builtins[name]
+		return builtin, ok
+	}
+	builtin, ok := st.builtins[name]
+	return builtin, ok
+}
+
+// ノードを1つ評価する前に呼ばれ、制限を超えていればエラーを返す
+func (st *state) step() *object.Error {
+	if st == nil {
+		return nil
+	}
+	if st.err != nil {
+		return st.err
+	}
+
+	select {
+	case <-st.done:
+		return st.fail(object.TimeoutError, "evaluation aborted: %s", st.ctx.Err())
+	default:
+	}
+
+	st.steps++
+	if st.limits.MaxSteps > 0 && st.steps > st.limits.MaxSteps {
+		return st.fail(object.StepLimitError, "step limit exceeded: %d steps", st.limits.MaxSteps)
+	}
+	return nil
+}
+
+// 関数呼び出しの前に呼ばれ、呼び出しの深さが上限を超えていればエラーを返す
+func (st *state) enterCall(fn *object.Function) *object.Error {
+	if st == nil {
+		return nil
+	}
+
+	st.depth++
+	if st.limits.MaxDepth > 0 && st.depth > st.limits.MaxDepth {
+		st.depth--
+		return st.fail(object.StackOverflowError, "stack overflow: call depth exceeded %d in `%s`", st.limits.MaxDepth, functionName(fn))
+	}
+	return nil
+}
+
+func (st *state) leaveCall() {
+	if st != nil {
+		st.depth--
+	}
+}
+
+func (st *state) fail(kind object.ErrorKind, format string, a ...any) *object.Error {
+	st.err = newError(format, a...)
+	st.err.Kind = kind
+	return st.err
+}
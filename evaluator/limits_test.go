@@ -0,0 +1,83 @@
+package evaluator_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mahiro72/monkey-lang/evaluator"
+	"github.com/mahiro72/monkey-lang/lexer"
+	"github.com/mahiro72/monkey-lang/object"
+	"github.com/mahiro72/monkey-lang/parser"
+	testingHelper "github.com/mahiro72/monkey-lang/testing"
+)
+
+func TestEvalContext(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		limits      evaluator.Limits
+		expectedObj object.Object
+	}{
+		{
+			name:        "success: 制限内の評価",
+			input:       `let f = fn(n) { if (n < 1) { 0 } else { n + f(n - 1) } }; f(10)`,
+			limits:      evaluator.Limits{MaxSteps: 10000, MaxDepth: 20},
+			expectedObj: &object.Integer{Value: 55},
+		},
+		{
+			name:   "failure: 無限再帰は呼び出しの深さで打ち切る",
+			input:  `let f = fn(x) { f(x) }; f(1)`,
+			limits: evaluator.Limits{MaxDepth: 100},
+			expectedObj: &object.Error{
+				Message: "stack overflow: call depth exceeded 100 in `f`",
+				Kind:    object.StackOverflowError,
+			},
+		},
+		{
+			name:   "failure: ステップ数の上限",
+			input:  `let f = fn(n) { if (n < 1) { 0 } else { n + f(n - 1) } }; f(1000)`,
+			limits: evaluator.Limits{MaxSteps: 500},
+			expectedObj: &object.Error{
+				Message: "step limit exceeded: 500 steps",
+				Kind:    object.StepLimitError,
+			},
+		},
+		{
+			name:   "failure: 引数の評価中に上限を超えてもエラーが伝播する",
+			input:  `let f = fn(x) { [f(x), f(x)] }; let r = f(1); r + 1`,
+			limits: evaluator.Limits{MaxSteps: 1000},
+			expectedObj: &object.Error{
+				Message: "step limit exceeded: 1000 steps",
+				Kind:    object.StepLimitError,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+			program := p.ParseProgram()
+			env := object.NewEnvironment()
+			obj := evaluator.EvalContext(context.Background(), program, env, evaluator.Options{Limits: tt.limits})
+
+			testingHelper.AssertEqual(t, tt.expectedObj, obj)
+		})
+	}
+}
+
+func TestEvalContextCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// 深さの制限だけでは止まらない、末尾で枝分かれし続ける再帰
+	l := lexer.New(`let f = fn(n) { if (n < 1) { 0 } else { f(n - 1) + f(n - 1) } }; f(100)`)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	obj := evaluator.EvalContext(ctx, program, object.NewEnvironment(), evaluator.Options{Limits: evaluator.Limits{MaxDepth: 200}})
+
+	testingHelper.AssertEqual(t, &object.Error{
+		Message: "evaluation aborted: context deadline exceeded",
+		Kind:    object.TimeoutError,
+	}, obj)
+}
@@ -0,0 +1,109 @@
+package evaluator
+
+import (
+	"github.com/mahiro72/monkey-lang/ast"
+	"github.com/mahiro72/monkey-lang/object"
+)
+
+// ループはletと同じく値を持たない文として評価する
+// for文はループ全体で1つ、for-in文は繰り返しごとに新しいスコープを作り、初期化文やループ変数の束縛、本体のletが外へ漏れないようにする
+// 外側の変数は代入式で更新する。while文は条件式にletを書けないため、if式のブロックと同じく新しいスコープを作らない
+
+func evalWhileStatement(ws *ast.WhileStatement, env *object.Environment, st *state) object.Object {
+	for {
+		condition := eval(ws.Condition, env, st)
+		if isError(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return nil
+		}
+
+		if done, result := loopBodyResult(eval(ws.Body, env, st)); done {
+			return result
+		}
+	}
+}
+
+func evalForStatement(fs *ast.ForStatement, outer *object.Environment, st *state) object.Object {
+	env := object.NewEnclosedEnvironment(outer)
+	if fs.Init != nil {
+		if init := eval(fs.Init, env, st); isError(init) {
+			return init
+		}
+	}
+
+	for {
+		// 条件を省略した場合は無限ループになる
+		if fs.Condition != nil {
+			condition := eval(fs.Condition, env, st)
+			if isError(condition) {
+				return condition
+			}
+			if !isTruthy(condition) {
+				return nil
+			}
+		}
+
+		if done, result := loopBodyResult(eval(fs.Body, env, st)); done {
+			return result
+		}
+
+		if fs.Post != nil {
+			if post := eval(fs.Post, env, st); isError(post) {
+				return post
+			}
+		}
+	}
+}
+
+// 配列は要素ごとに、文字列は1文字ずつの文字列としてループ変数に束縛する
+func evalForInStatement(fs *ast.ForInStatement, env *object.Environment, st *state) object.Object {
+	iterable := eval(fs.Iterable, env, st)
+	if isError(iterable) {
+		return iterable
+	}
+
+	var elements []object.Object
+	switch iterable := iterable.(type) {
+	case *object.Array:
+		// 本体で配列が変更されても、ループ開始時点の要素を辿る
+		elements = append(elements, iterable.Elements...)
+	case *object.String:
+		for _, ch := range iterable.Value {
+			elements = append(elements, &object.String{Value: string(ch)})
+		}
+	default:
+		return newError("cannot iterate over %s", iterable.Type())
+	}
+
+	for _, element := range elements {
+		// 繰り返しごとに新しいスコープを作り、本体のクロージャがその回の要素を捕捉するようにする
+		loopEnv := object.NewEnclosedEnvironment(env)
+		loopEnv.Set(fs.Variable.Value, element)
+
+		if done, result := loopBodyResult(eval(fs.Body, loopEnv, st)); done {
+			return result
+		}
+	}
+	return nil
+}
+
+// ループ本体の評価結果から、ループを終えるかどうかとループ全体の評価結果を返す
+// returnとエラーはそのまま外側へ伝える
+func loopBodyResult(result object.Object) (bool, object.Object) {
+	switch result.(type) {
+	case *object.Break:
+		return true, nil
+	case *object.ReturnValue, *object.Error:
+		return true, result
+	default:
+		return false, nil // continueも含めて次の繰り返しへ進む
+	}
+}
+
+// ループの外まで伝わったbreak, continueをエラーにする
+// 構文解析器がループの外のbreak, continueを拒否するので、通常は起こらない
+func loopSignalError(signal object.Object) *object.Error {
+	return newError("%s outside loop", signal.Inspect())
+}
@@ -0,0 +1,211 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/mahiro72/monkey-lang/ast"
+	"github.com/mahiro72/monkey-lang/evaluator"
+	"github.com/mahiro72/monkey-lang/lexer"
+	"github.com/mahiro72/monkey-lang/object"
+	"github.com/mahiro72/monkey-lang/parser"
+	testingHelper "github.com/mahiro72/monkey-lang/testing"
+	"github.com/mahiro72/monkey-lang/token"
+)
+
+func TestEvalLoopStatements(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedObj object.Object
+	}{
+		{
+			name:        "success: while文",
+			input:       `let i = 0; let sum = 0; while (i < 5) { let sum = sum + i; let i = i + 1; } sum`,
+			expectedObj: &object.Integer{Value: 10},
+		},
+		{
+			name:        "success: 条件が最初から偽のwhile文は本体を評価しない",
+			input:       `let x = 1; while (false) { let x = 2; } x`,
+			expectedObj: &object.Integer{Value: 1},
+		},
+		{
+			name:        "success: for文",
+			input:       `let sum = 0; for (let i = 1; i <= 10; let i = i + 1) { sum = sum + i; } sum`,
+			expectedObj: &object.Integer{Value: 55},
+		},
+		{
+			name:        "success: break",
+			input:       `let i = 0; while (true) { if (i == 3) { break; } let i = i + 1; } i`,
+			expectedObj: &object.Integer{Value: 3},
+		},
+		{
+			name:        "success: continueでもfor文の後処理は評価する",
+			input:       `let sum = 0; for (let i = 0; i < 6; let i = i + 1) { if (i % 2 == 0) { continue; } sum = sum + i; } sum`,
+			expectedObj: &object.Integer{Value: 9},
+		},
+		{
+			name:        "success: 条件を省略したfor文はbreakまで繰り返す",
+			input:       `let n = 0; for (;;) { n = n + 1; if (n >= 100) { break } } n`,
+			expectedObj: &object.Integer{Value: 100},
+		},
+		{
+			name:        "success: 配列のfor-in",
+			input:       `let sum = 0; for (x in [1, 2, 3]) { sum = sum + x; } sum`,
+			expectedObj: &object.Integer{Value: 6},
+		},
+		{
+			name:        "success: 文字列のfor-inは1文字ずつ",
+			input:       `let s = ""; for (c in "日本語") { s = c + s; } s`,
+			expectedObj: &object.String{Value: "語本日"},
+		},
+		{
+			name:        "success: breakは最も内側のループだけを抜ける",
+			input:       `let count = 0; for (x in [1, 2, 3]) { for (y in [1, 2, 3]) { if (y > x) { break; } count = count + 1; } } count`,
+			expectedObj: &object.Integer{Value: 6},
+		},
+		{
+			name:        "success: ループ内のreturnは関数から戻る",
+			input:       `let find = fn(arr, v) { for (x in arr) { if (x == v) { return true; } } false }; [find([1, 2], 2), find([1, 2], 3)]`,
+			expectedObj: &object.Array{Elements: []object.Object{&object.Boolean{Value: true}, &object.Boolean{Value: false}}},
+		},
+		{
+			name:        "success: 再帰の代わりにループで大きな回数を繰り返せる",
+			input:       `let i = 0; while (i < 100000) { let i = i + 1; } i`,
+			expectedObj: &object.Integer{Value: 100000},
+		},
+		{
+			name:        "success: for-inのループ変数は外側の同名の変数を変更しない",
+			input:       `let i = 10; for (i in [1, 2]) {} i`,
+			expectedObj: &object.Integer{Value: 10},
+		},
+		{
+			name:        "success: for文の初期化文のletはループの外へ漏れない",
+			input:       `let i = 10; for (let i = 0; i < 3; let i = i + 1) {} i`,
+			expectedObj: &object.Integer{Value: 10},
+		},
+		{
+			name:        "success: for文の本体のletはループの外へ漏れない",
+			input:       `let x = 1; for (y in [1, 2]) { let x = y; } x`,
+			expectedObj: &object.Integer{Value: 1},
+		},
+		{
+			name:        "success: for-inの本体のクロージャはその回のループ変数を捕捉する",
+			input:       `let fs = []; for (x in [1, 2, 3]) { fs = push(fs, fn() { x }) } [fs[0](), fs[1](), fs[2]()]`,
+			expectedObj: &object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.Integer{Value: 2}, &object.Integer{Value: 3}}},
+		},
+		{
+			name:        "failure: for-inの本体のletは次の繰り返しに持ち越さない",
+			input:       `for (x in [1, 2]) { if (x == 2) { y } let y = x; }`,
+			expectedObj: &object.Error{Message: "identifier not found: y"},
+		},
+		{
+			name:        "failure: for-inのループ変数はループの後に参照できない",
+			input:       `for (x in [1, 2]) {} x`,
+			expectedObj: &object.Error{Message: "identifier not found: x"},
+		},
+		{
+			name:        "success: 関数の引数の中のcontinue",
+			input:       `let r = []; for (x in [1, 2, 3]) { r = push(r, if (x == 2) { continue } else { x }) } r`,
+			expectedObj: &object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.Integer{Value: 3}}},
+		},
+		{
+			name:        "success: 関数の引数の中のbreak",
+			input:       `let i = 0; while (i < 3) { len(if (true) { break }); i += 1 } i`,
+			expectedObj: &object.Integer{Value: 0},
+		},
+		{
+			name:        "success: let文の値の中のbreak",
+			input:       `let i = 0; while (true) { let x = if (true) { break }; i += 1 } i`,
+			expectedObj: &object.Integer{Value: 0},
+		},
+		{
+			name:        "success: 代入の値の中のbreakは代入しない",
+			input:       `let x = 1; while (true) { x = if (true) { break } } x`,
+			expectedObj: &object.Integer{Value: 1},
+		},
+		{
+			name:        "success: 複合代入の値の中のbreak",
+			input:       `let x = 1; while (true) { x += if (true) { break } } x`,
+			expectedObj: &object.Integer{Value: 1},
+		},
+		{
+			name:        "success: 添字への代入の中のcontinue",
+			input:       `let a = [0, 0]; for (i in [0, 1]) { a[if (i == 0) { continue } else { i }] = 5 } a`,
+			expectedObj: &object.Array{Elements: []object.Object{&object.Integer{Value: 0}, &object.Integer{Value: 5}}},
+		},
+		{
+			name:        "success: 中置式の右辺の中のcontinue",
+			input:       `let sum = 0; for (x in [1, 2, 3]) { sum = sum + (if (x == 2) { continue } else { x }) } sum`,
+			expectedObj: &object.Integer{Value: 4},
+		},
+		{
+			name:        "success: 中置式の左辺の中のbreak",
+			input:       `let n = 0; for (x in [1, 2, 3]) { n = (if (x == 2) { break } else { x }) + n } n`,
+			expectedObj: &object.Integer{Value: 1},
+		},
+		{
+			name:        "success: 前置式の中のbreak",
+			input:       `let n = 0; for (x in [1, 2, 3]) { n = -(if (x == 2) { break } else { x }) } n`,
+			expectedObj: &object.Integer{Value: -1},
+		},
+		{
+			name:        "success: 添字の中のcontinue",
+			input:       `let a = [10, 20, 30]; let sum = 0; for (i in [0, 1, 2]) { sum += a[if (i == 1) { continue } else { i }] } sum`,
+			expectedObj: &object.Integer{Value: 40},
+		},
+		{
+			name:        "success: 配列とハッシュの要素の中のcontinue",
+			input:       `let n = 0; for (x in [1, 2]) { [if (x == 1) { continue } else { x }]; {"a": if (x == 1) { continue } else { x }}; n += 1 } n`,
+			expectedObj: &object.Integer{Value: 1},
+		},
+		{
+			name:        "success: 関数内のlet文の値の中のreturn",
+			input:       `let f = fn() { let x = if (true) { return 1 }; 2 }; f()`,
+			expectedObj: &object.Integer{Value: 1},
+		},
+		{
+			name:        "success: ループ自体は値を持たない",
+			input:       `let f = fn() { while (false) {} }; f()`,
+			expectedObj: &object.Null{},
+		},
+		{
+			name:        "failure: 条件のエラー",
+			input:       `while (x) {}`,
+			expectedObj: &object.Error{Message: "identifier not found: x"},
+		},
+		{
+			name:        "failure: 本体のエラーでループを終える",
+			input:       `for (x in [1, 0]) { 1 / x }`,
+			expectedObj: &object.Error{Message: "division by zero: 1 / 0"},
+		},
+		{
+			name:        "failure: 繰り返せない値",
+			input:       `for (x in 1) {}`,
+			expectedObj: &object.Error{Message: "cannot iterate over INTEGER"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+			program := p.ParseProgram()
+			testingHelper.AssertEqual(t, []string{}, p.Errors())
+
+			env := object.NewEnvironment()
+			obj := evaluator.Eval(program, env)
+
+			testingHelper.AssertEqual(t, tt.expectedObj, obj)
+		})
+	}
+}
+
+// 構文解析器を通さずに組み立てたASTでも、break, continueはループの外へ伝わらない
+func TestEvalLoopSignalOutsideLoop(t *testing.T) {
+	breakStmt := &ast.BreakStatement{Token: token.Token{Type: token.BREAK, Literal: "break"}}
+	program := &ast.Program{Statements: []ast.Statement{breakStmt}}
+
+	obj := evaluator.Eval(program, object.NewEnvironment())
+
+	testingHelper.AssertEqual(t, &object.Error{Message: "break outside loop"}, obj)
+}
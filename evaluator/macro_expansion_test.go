@@ -0,0 +1,202 @@
+package evaluator_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mahiro72/monkey-lang/ast"
+	"github.com/mahiro72/monkey-lang/evaluator"
+	"github.com/mahiro72/monkey-lang/lexer"
+	"github.com/mahiro72/monkey-lang/object"
+	"github.com/mahiro72/monkey-lang/parser"
+	testingHelper "github.com/mahiro72/monkey-lang/testing"
+)
+
+func parseProgram(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	testingHelper.AssertEqual(t, []string{}, p.Errors())
+	return program
+}
+
+func TestDefineMacros(t *testing.T) {
+	input := `
+		let number = 1;
+		let function = fn(x, y) { x + y };
+		let mymacro = macro(x, y) { x + y; };
+	`
+	env := object.NewEnvironment()
+	program := parseProgram(t, input)
+
+	evaluator.DefineMacros(program, env)
+
+	testingHelper.AssertEqual(t, 2, len(program.Statements))
+	_, ok := env.Get("number")
+	testingHelper.AssertEqual(t, false, ok)
+	_, ok = env.Get("function")
+	testingHelper.AssertEqual(t, false, ok)
+
+	obj, ok := env.Get("mymacro")
+	if !ok {
+		t.Fatalf("macro not in environment")
+	}
+	macro, ok := obj.(*object.Macro)
+	if !ok {
+		t.Fatalf("expected *object.Macro, got %T", obj)
+	}
+	testingHelper.AssertEqual(t, "(x + y)", macro.Body.String())
+}
+
+func TestExpandMacros(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "success: 引数を含まないマクロ",
+			input: `
+				let infixExpression = macro() { quote(1 + 2); };
+				infixExpression();
+			`,
+			expected: `(1 + 2)`,
+		},
+		{
+			name: "success: 引数を入れ替えるマクロ",
+			input: `
+				let reverse = macro(a, b) { quote(unquote(b) - unquote(a)); };
+				reverse(2 + 2, 10 - 5);
+			`,
+			expected: `(10 - 5) - (2 + 2)`,
+		},
+		{
+			name: "success: unlessマクロ",
+			input: `
+				let unless = macro(cond, consequence, alternative) {
+					quote(if (!(unquote(cond))) {
+						unquote(consequence);
+					} else {
+						unquote(alternative);
+					});
+				};
+				unless(10 > 5, puts("not greater"), puts("greater"));
+			`,
+			expected: `if (!(10 > 5)) { puts("not greater") } else { puts("greater") }`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := parseProgram(t, tt.expected)
+			program := parseProgram(t, tt.input)
+
+			env := object.NewEnvironment()
+			evaluator.DefineMacros(program, env)
+			expanded, err := evaluator.ExpandMacros(context.Background(), program, env, evaluator.Options{})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Message)
+			}
+
+			testingHelper.AssertEqual(t, expected.String(), expanded.String())
+		})
+	}
+}
+
+func TestExpandMacrosErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		limits   evaluator.Limits
+		expected *object.Error
+	}{
+		{
+			name: "failure: Quoteを返さないマクロ",
+			input: `
+				let m = macro() { 1 };
+				m();
+			`,
+			expected: &object.Error{Message: "macro must return QUOTE, got INTEGER"},
+		},
+		{
+			name: "failure: マクロの引数の数",
+			input: `
+				let m = macro(x) { x };
+				m();
+			`,
+			expected: &object.Error{Message: "wrong number of arguments to `m`: want=1, got=0"},
+		},
+		{
+			name: "failure: マクロの評価のエラー",
+			input: `
+				let m = macro(x) { quote(unquote(x) + unquote(y)) };
+				m(1);
+			`,
+			expected: &object.Error{Message: "identifier not found: y"},
+		},
+		{
+			name: "failure: マクロ内の無限再帰は呼び出しの深さで打ち切る",
+			input: `
+				let m = macro() { let f = fn(x) { f(x) }; f(1) };
+				m();
+			`,
+			limits: evaluator.Limits{MaxDepth: 100},
+			expected: &object.Error{
+				Message: "stack overflow: call depth exceeded 100 in `f`",
+				Kind:    object.StackOverflowError,
+			},
+		},
+		{
+			name: "failure: マクロ内の無限ループはステップ数で打ち切る",
+			input: `
+				let m = macro() { while (true) {} };
+				m();
+			`,
+			limits: evaluator.Limits{MaxSteps: 1000},
+			expected: &object.Error{
+				Message: "step limit exceeded: 1000 steps",
+				Kind:    object.StepLimitError,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := parseProgram(t, tt.input)
+
+			env := object.NewEnvironment()
+			evaluator.DefineMacros(program, env)
+			_, err := evaluator.ExpandMacros(context.Background(), program, env, evaluator.Options{Limits: tt.limits})
+
+			testingHelper.AssertEqual(t, tt.expected, err)
+		})
+	}
+}
+
+// マクロで定義した制御構文を展開した後に評価する
+func TestEvalExpandedMacros(t *testing.T) {
+	input := `
+		let unless = macro(cond, consequence, alternative) {
+			quote(if (!(unquote(cond))) { unquote(consequence) } else { unquote(alternative) });
+		};
+		unless(1 > 2, "ok", undefined);
+	`
+	program := parseProgram(t, input)
+
+	macroEnv := object.NewEnvironment()
+	evaluator.DefineMacros(program, macroEnv)
+	expanded, err := evaluator.ExpandMacros(context.Background(), program, macroEnv, evaluator.Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Message)
+	}
+
+	obj := evaluator.Eval(expanded, object.NewEnvironment())
+	testingHelper.AssertEqual(t, &object.String{Value: "ok"}, obj)
+}
+
+func TestEvalMacroLiteralOutsideDefinition(t *testing.T) {
+	program := parseProgram(t, `let f = fn() { macro(x) { x } }; f()`)
+
+	obj := evaluator.Eval(program, object.NewEnvironment())
+	testingHelper.AssertEqual(t, &object.Error{Message: "macro literal must be bound by a top-level let statement"}, obj)
+}
@@ -0,0 +1,112 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/mahiro72/monkey-lang/ast"
+	"github.com/mahiro72/monkey-lang/object"
+	"github.com/mahiro72/monkey-lang/token"
+)
+
+// quoteという名前の束縛が無い場合のみ、quote(...)の呼び出しをquoteとして扱う
+// quoteは予約語ではないので、ユーザーが定義した同名の関数の呼び出しは通常の関数呼び出しになる
+func isQuoteCall(node *ast.CallExpression, env *object.Environment) bool {
+	ident, ok := node.Function.(*ast.Identifier)
+	if !ok || ident.Value != "quote" {
+		return false
+	}
+	_, defined := env.Get(ident.Value)
+	return !defined
+}
+
+// quote(<expression>) は引数を評価せず、ASTのノードのままobject.Quoteに包んで返す
+// ただし引数の中のunquote(<expression>)は評価し、その結果をASTのノードに戻して埋め込む
+func evalQuoteCall(node *ast.CallExpression, env *object.Environment, st *state) object.Object {
+	if len(node.Arguments) != 1 {
+		return wrongNumberOfArgumentsError("quote", 1, len(node.Arguments))
+	}
+	return quote(node.Arguments[0], env, st)
+}
+
+func quote(node ast.Node, env *object.Environment, st *state) object.Object {
+	var err *object.Error
+
+	node = ast.Modify(node, func(node ast.Node) ast.Node {
+		if err != nil || !isUnquoteCall(node) {
+			return node
+		}
+
+		call := node.(*ast.CallExpression)
+		if len(call.Arguments) != 1 {
+			err = wrongNumberOfArgumentsError("unquote", 1, len(call.Arguments))
+			return node
+		}
+
+		unquoted := eval(call.Arguments[0], env, st)
+		if isError(unquoted) {
+			err = unquoted.(*object.Error)
+			return node
+		}
+
+		converted, ok := convertObjectToASTNode(unquoted)
+		if !ok {
+			err = newError("cannot unquote %s into AST", unquoted.Type())
+			return node
+		}
+		return converted
+	})
+	if err != nil {
+		return err
+	}
+
+	return &object.Quote{Node: node}
+}
+
+func isUnquoteCall(node ast.Node) bool {
+	call, ok := node.(*ast.CallExpression)
+	if !ok {
+		return false
+	}
+	ident, ok := call.Function.(*ast.Identifier)
+	return ok && ident.Value == "unquote"
+}
+
+// unquoteの評価結果をASTのノードに戻す。リテラルで表せない値の場合はfalseを返す
+func convertObjectToASTNode(obj object.Object) (ast.Node, bool) {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		t := token.Token{Type: token.INT, Literal: fmt.Sprintf("%d", obj.Value)}
+		return &ast.IntegerLiteral{Token: t, Value: obj.Value}, true
+	case *object.Float:
+		t := token.Token{Type: token.FLOAT, Literal: obj.Inspect()}
+		return &ast.FloatLiteral{Token: t, Value: obj.Value}, true
+	case *object.String:
+		t := token.Token{Type: token.STRING, Literal: obj.Value}
+		return &ast.StringLiteral{Token: t, Value: obj.Value}, true
+	case *object.Boolean:
+		var t token.Token
+		if obj.Value {
+			t = token.Token{Type: token.TRUE, Literal: "true"}
+		} else {
+			t = token.Token{Type: token.FALSE, Literal: "false"}
+		}
+		return &ast.Boolean{Token: t, Value: obj.Value}, true
+	case *object.Array:
+		elements := make([]ast.Expression, len(obj.Elements))
+		for i, e := range obj.Elements {
+			node, ok := convertObjectToASTNode(e)
+			if !ok {
+				return nil, false
+			}
+			if elements[i], ok = node.(ast.Expression); !ok {
+				return nil, false
+			}
+		}
+		t := token.Token{Type: token.LBRACKET, Literal: "["}
+		return &ast.ArrayLiteral{Token: t, Elements: elements}, true
+	case *object.Quote:
+		return obj.Node, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,111 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/mahiro72/monkey-lang/evaluator"
+	"github.com/mahiro72/monkey-lang/lexer"
+	"github.com/mahiro72/monkey-lang/object"
+	"github.com/mahiro72/monkey-lang/parser"
+	testingHelper "github.com/mahiro72/monkey-lang/testing"
+)
+
+func TestQuoteUnquote(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "success: 引数を評価しない", input: `quote(5 + 8)`, expected: `(5 + 8)`},
+		{name: "success: 識別子", input: `quote(foobar)`, expected: `foobar`},
+		{name: "success: unquoteは評価する", input: `quote(8 + unquote(4 + 4))`, expected: `(8 + 8)`},
+		{name: "success: 束縛の参照", input: `let foo = 8; quote(unquote(foo) + foo)`, expected: `(8 + foo)`},
+		{name: "success: 真偽値", input: `quote(unquote(true == false))`, expected: `false`},
+		{name: "success: 文字列と浮動小数点数", input: `quote(unquote("a" + "b") + unquote(1.5))`, expected: `("ab" + 1.5)`},
+		{name: "success: 配列", input: `quote(unquote([1, 2 * 3]))`, expected: `[1, 6]`},
+		{name: "success: 入れ子のquote", input: `let q = quote(4 + 4); quote(unquote(4 + 4) + unquote(q))`, expected: `(8 + (4 + 4))`},
+		{name: "success: 関数の中のquoteは呼び出しごとに置き換える", input: `let f = fn(x) { quote(unquote(x)) }; f(1); f(2)`, expected: `2`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.New(lexer.New(tt.input))
+			program := p.ParseProgram()
+			testingHelper.AssertEqual(t, []string{}, p.Errors())
+
+			obj := evaluator.Eval(program, object.NewEnvironment())
+
+			quote, ok := obj.(*object.Quote)
+			if !ok {
+				t.Fatalf("expected *object.Quote, got %T (%+v)", obj, obj)
+			}
+			testingHelper.AssertEqual(t, tt.expected, quote.Node.String())
+		})
+	}
+}
+
+func TestQuoteUnquoteErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedObj object.Object
+	}{
+		{
+			name:        "failure: quoteの引数の数",
+			input:       `quote(1, 2)`,
+			expectedObj: &object.Error{Message: "wrong number of arguments to `quote`: want=1, got=2"},
+		},
+		{
+			name:        "failure: unquoteの評価のエラー",
+			input:       `quote(unquote(undefined))`,
+			expectedObj: &object.Error{Message: "identifier not found: undefined"},
+		},
+		{
+			name:        "failure: ASTに戻せない値",
+			input:       `quote(unquote(fn(x) { x }))`,
+			expectedObj: &object.Error{Message: "cannot unquote FUNCTION into AST"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.New(lexer.New(tt.input))
+			program := p.ParseProgram()
+			testingHelper.AssertEqual(t, []string{}, p.Errors())
+
+			obj := evaluator.Eval(program, object.NewEnvironment())
+			testingHelper.AssertEqual(t, tt.expectedObj, obj)
+		})
+	}
+}
+
+// quoteという名前の束縛がある場合は、通常の関数呼び出しとして評価する
+func TestQuoteShadowedByBinding(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedObj object.Object
+	}{
+		{
+			name:        "success: let文で定義した関数",
+			input:       `let quote = fn(x) { x + 1 }; quote(1)`,
+			expectedObj: &object.Integer{Value: 2},
+		},
+		{
+			name:        "success: 仮引数",
+			input:       `let f = fn(quote) { quote(2) }; f(fn(x) { x * 3 })`,
+			expectedObj: &object.Integer{Value: 6},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.New(lexer.New(tt.input))
+			program := p.ParseProgram()
+			testingHelper.AssertEqual(t, []string{}, p.Errors())
+
+			obj := evaluator.Eval(program, object.NewEnvironment())
+			testingHelper.AssertEqual(t, tt.expectedObj, obj)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mahiro72/monkey-lang/ast"
+	"github.com/mahiro72/monkey-lang/object"
+)
+
+// 評価中に発生したpanicと、その時に評価していたノード
+type evalPanic struct {
+	node  ast.Node
+	value any
+}
+
+// Evalの各呼び出しでdeferされ、最も内側で評価していたノードをpanicに付与する
+func annotatePanic(node ast.Node) {
+	if r := recover(); r != nil {
+		if _, ok := r.(*evalPanic); !ok {
+			r = &evalPanic{node: node, value: r}
+		}
+		panic(r)
+	}
+}
+
+// Evalと同じく評価を行うが、評価中のpanicを内部エラーのobject.Errorに変換して返す
+// Goのスタックオーバーフローはrecoverできないため、関数呼び出しの深さをDefaultMaxDepthに制限する
+// ホストのプロセスをスクリプトの不具合で停止させたくない場合はこちらを使う
+// ただし無限ループは止まらないので、実行時間も制限したい場合はEvalContextを使う
+func SafeEval(node ast.Node, env *object.Environment) object.Object {
+	opts := Options{Limits: Limits{MaxDepth: DefaultMaxDepth}}
+	return safeEval(node, env, newState(context.Background(), opts))
+}
+
+func safeEval(node ast.Node, env *object.Environment, st *state) (result object.Object) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		p, ok := r.(*evalPanic)
+		if !ok {
+			p = &evalPanic{node: node, value: r}
+		}
+		result = &object.Error{
+			Message: fmt.Sprintf("internal error: %v (while evaluating %s)", p.value, nodeString(p.node)),
+			Kind:    object.InternalError,
+			Node:    p.node,
+		}
+	}()
+
+	return eval(node, env, st)
+}
+
+// String()自体がpanicしてもエラーメッセージを組み立てられるようにする
+func nodeString(node ast.Node) (s string) {
+	defer func() {
+		if recover() != nil {
+			s = fmt.Sprintf("%T", node)
+		}
+	}()
+	if node == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%q", node.String())
+}
@@ -0,0 +1,118 @@
+package evaluator_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mahiro72/monkey-lang/ast"
+	"github.com/mahiro72/monkey-lang/evaluator"
+	"github.com/mahiro72/monkey-lang/lexer"
+	"github.com/mahiro72/monkey-lang/object"
+	"github.com/mahiro72/monkey-lang/parser"
+	testingHelper "github.com/mahiro72/monkey-lang/testing"
+	"github.com/mahiro72/monkey-lang/token"
+)
+
+func TestSafeEval(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedObj object.Object
+	}{
+		{
+			name:        "success: 空のブロックはNULLになる",
+			input:       `let x = if (true) {}; x + 1`,
+			expectedObj: &object.Error{Message: "type mismatch: NULL + INTEGER"},
+		},
+		{
+			name:        "success: let文で終わる関数はNULLを返す",
+			input:       `let f = fn() { let y = 1; }; f() == f()`,
+			expectedObj: &object.Boolean{Value: true},
+		},
+		{
+			name:        "success: 末尾にセミコロンが無いreturn",
+			input:       `return 10`,
+			expectedObj: &object.Integer{Value: 10},
+		},
+		{
+			name:  "failure: 無限再帰はホストのスタックが溢れる前に打ち切る",
+			input: `let f = fn(x) { f(x) }; f(1)`,
+			expectedObj: &object.Error{
+				Message: "stack overflow: call depth exceeded 10000 in `f`",
+				Kind:    object.StackOverflowError,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+			program := p.ParseProgram()
+			env := object.NewEnvironment()
+			obj := evaluator.SafeEval(program, env)
+
+			testingHelper.AssertEqual(t, tt.expectedObj, obj)
+		})
+	}
+}
+
+func TestSafeEvalRecoversPanic(t *testing.T) {
+	// 構文解析器が生成しない不正なAST (左辺がnil) でpanicを起こす
+	infix := &ast.InfixExpression{
+		Token:    token.Token{Type: token.PLUS, Literal: "+"},
+		Operator: "+",
+		Right: &ast.IntegerLiteral{
+			Token: token.Token{Type: token.INT, Literal: "1"},
+			Value: 1,
+		},
+	}
+	program := &ast.Program{Statements: []ast.Statement{
+		&ast.ExpressionStatement{Token: infix.Token, Expression: infix},
+	}}
+
+	obj := evaluator.SafeEval(program, object.NewEnvironment())
+
+	errObj, ok := obj.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error. got=%T (%+v)", obj, obj)
+	}
+	if !strings.HasPrefix(errObj.Message, "internal error: runtime error:") {
+		t.Errorf("unexpected message: %q", errObj.Message)
+	}
+	testingHelper.AssertEqual(t, object.InternalError, errObj.Kind)
+	testingHelper.AssertEqual(t, ast.Node(infix), errObj.Node)
+}
+
+// 構文エラーの無いプログラムは、評価結果がエラーであっても内部エラー(panic)にはならない
+func FuzzSafeEval(f *testing.F) {
+	seeds := []string{
+		`let x = if (true) {}; x + 1`,
+		`let add = fn(a, b) { a + b }; add(1)`,
+		`let a = [1, 2, 3]; a[-1] + a[10]`,
+		`{"a": 1, true: [fn(x) { x }]}["a"]`,
+		`puts(len("hello"), first([]), rest([1]), push([], 1))`,
+		`if (1 > 2) { 10 } else { return "s" + "t"; }`,
+		`1 / 0; -true; !fn() {}`,
+		`let f = fn(x) { f(x) }; f(1)`,
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		p := parser.New(lexer.New(input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			return
+		}
+
+		// SafeEvalと同じ深さの制限で評価する。SafeEvalは無限ループで止まらないため、ステップ数の上限だけ加える
+		opts := evaluator.Options{Limits: evaluator.Limits{MaxSteps: 1000000, MaxDepth: evaluator.DefaultMaxDepth}, Output: io.Discard}
+		obj := evaluator.EvalContext(context.Background(), program, object.NewEnvironment(), opts)
+		if errObj, ok := obj.(*object.Error); ok && errObj.Kind == object.InternalError {
+			t.Fatalf("input %q caused %s", input, errObj.Message)
+		}
+	})
+}
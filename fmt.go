@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mahiro72/monkey-lang/printer"
+)
+
+// monkey fmt [-w] [-d] [files...]
+// ファイルを整形して標準出力に書き出す。ファイルを指定しない場合は標準入力を整形する
+// -wは整形結果でファイルを書き換え、-dは整形前後の差分を出力する
+func runFmt(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	write := flags.Bool("w", false, "write result to the source file instead of stdout")
+	diff := flags.Bool("d", false, "display diffs instead of rewriting files")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(stderr, "fmt: cannot use -w with standard input")
+			return 2
+		}
+		src, err := io.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		if err := formatFile("<stdin>", src, false, *diff, stdout); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	status := 0
+	for _, filename := range flags.Args() {
+		src, err := os.ReadFile(filename)
+		if err == nil {
+			err = formatFile(filename, src, *write, *diff, stdout)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			status = 1
+		}
+	}
+	return status
+}
+
+func formatFile(filename string, src []byte, write, diff bool, out io.Writer) error {
+	formatted, err := printer.Format(filename, src)
+	if err != nil {
+		return err
+	}
+
+	if diff {
+		unifiedDiff(out, filename+".orig", filename, string(src), string(formatted))
+	}
+	if write {
+		if bytes.Equal(src, formatted) {
+			return nil
+		}
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filename, formatted, info.Mode().Perm())
+	}
+	if !diff {
+		_, err = out.Write(formatted)
+	}
+	return err
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	testingHelper "github.com/mahiro72/monkey-lang/testing"
+)
+
+func TestRunFmt(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		stdin          string
+		expectedStatus int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:           "success: 標準入力を整形する",
+			args:           []string{},
+			stdin:          "let x=1\nputs(x)",
+			expectedStatus: 0,
+			expectedStdout: "let x = 1;\nputs(x);\n",
+		},
+		{
+			name:           "success: 差分を出力する",
+			args:           []string{"-d"},
+			stdin:          "let x = 1;\nputs(x)\n",
+			expectedStatus: 0,
+			expectedStdout: "--- <stdin>.orig\n+++ <stdin>\n@@ -1,2 +1,2 @@\n let x = 1;\n-puts(x)\n+puts(x);\n",
+		},
+		{
+			name:           "success: 整形済みの場合は差分を出力しない",
+			args:           []string{"-d"},
+			stdin:          "let x = 1;\n",
+			expectedStatus: 0,
+		},
+		{
+			name:           "failure: 構文エラー",
+			args:           []string{},
+			stdin:          "let = 1;",
+			expectedStatus: 1,
+			expectedStderr: "<stdin>:1:5: expected next token to be IDENT, got = instead\n",
+		},
+		{
+			name:           "failure: 標準入力は書き換えられない",
+			args:           []string{"-w"},
+			expectedStatus: 2,
+			expectedStderr: "fmt: cannot use -w with standard input\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			status := runFmt(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+
+			testingHelper.AssertEqual(t, tt.expectedStatus, status)
+			testingHelper.AssertEqual(t, tt.expectedStdout, stdout.String())
+			testingHelper.AssertEqual(t, tt.expectedStderr, stderr.String())
+		})
+	}
+}
+
+func TestRunFmtWrite(t *testing.T) {
+	dir := t.TempDir()
+	unformatted := filepath.Join(dir, "a.mk")
+	formatted := filepath.Join(dir, "b.mk")
+	invalid := filepath.Join(dir, "c.mk")
+	for name, src := range map[string]string{
+		unformatted: "let x=1",
+		formatted:   "let x = 1;\n",
+		invalid:     "let x",
+	} {
+		if err := os.WriteFile(name, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	status := runFmt([]string{"-w", unformatted, formatted, invalid}, nil, &stdout, &stderr)
+
+	testingHelper.AssertEqual(t, 1, status)
+	testingHelper.AssertEqual(t, "", stdout.String())
+	testingHelper.AssertEqual(t, invalid+":1:6: expected next token to be =, got EOF instead\n", stderr.String())
+
+	for name, expected := range map[string]string{
+		unformatted: "let x = 1;\n",
+		formatted:   "let x = 1;\n",
+		invalid:     "let x",
+	} {
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testingHelper.AssertEqual(t, expected, string(got))
+	}
+}
@@ -1,60 +1,322 @@
 package lexer
 
-import "github.com/mahiro72/monkey-lang/token"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mahiro72/monkey-lang/token"
+)
+
+// 字句解析中に見つかったエラーを受け取る関数
+type ErrorHandler func(pos token.Position, msg string)
 
 type Lexer struct {
 	input        string
-	position     int  // 入力における現在の位置
-	readPosition int  // これらか読み込む位置
-	ch           byte // 現在検査中の文字
+	filename     string // 位置情報に埋め込むファイル名
+	position     int    // 入力における現在の位置
+	readPosition int    // これらか読み込む位置
+	ch           rune   // 現在検査中の文字
+	line         int    // 現在検査中の文字の行番号
+	column       int    // 現在検査中の文字の列番号 (1始まり、文字単位)
+
+	errorHandler ErrorHandler // エラーの通知先 (nilの場合は通知しない)
+	emitComments bool         // trueの場合、コメントを読み飛ばさずCOMMENTトークンとして返す
 }
 
 func New(input string) *Lexer {
+	return NewWithFilename("", input)
+}
+
+// ファイル名付きで字句解析器を生成する。ファイル名は各トークンの位置情報に記録される
+func NewWithFilename(filename, input string) *Lexer {
 	l := &Lexer{
-		input: input,
+		input:    input,
+		filename: filename,
+		line:     1,
+		column:   1,
 	}
 	l.readChar()
 	return l
 }
 
+// 字句解析エラーの通知先を設定する
+func (l *Lexer) SetErrorHandler(h ErrorHandler) {
+	l.errorHandler = h
+}
+
+// コメントをCOMMENTトークンとして返すかどうかを設定する (デフォルトは読み飛ばす)
+// フォーマッタなど、コメントを保持したい場合に使う
+func (l *Lexer) SetEmitComments(emit bool) {
+	l.emitComments = emit
+}
+
+func (l *Lexer) error(pos token.Position, msg string) {
+	if l.errorHandler != nil {
+		l.errorHandler(pos, msg)
+	}
+}
+
 func (l *Lexer) readChar() {
+	// 列番号は行の先頭から数え直さず、1文字読み進めるごとに数える (長い行でも各位置をO(1)で求めるため)
+	if l.ch == '\n' {
+		l.line += 1
+		l.column = 1
+	} else if l.readPosition > 0 && l.position < len(l.input) {
+		l.column += 1 // 初回の呼び出しと、終端に到達した後は進めない
+	}
+	width := 1
 	if l.readPosition >= len(l.input) {
 		l.ch = 0 //終端に到達(ASCIIのNUL文字)
 	} else {
-		l.ch = l.input[l.readPosition]
+		// 不正なUTF-8のバイトはutf8.RuneErrorとして1バイトずつ読み進める
+		l.ch, width = utf8.DecodeRuneInString(l.input[l.readPosition:])
 	}
 	l.position = l.readPosition
-	l.readPosition += 1
+	l.readPosition += width
 }
 
+// 識別子を読み込む。2文字目以降には数字 (Unicodeの数字を含む) も使える
 func (l *Lexer) readIdentifier() string {
 	position := l.position
-	for isLetter(l.ch) {
+	for isLetter(l.ch) || unicode.IsDigit(l.ch) {
 		l.readChar()
 	}
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) readNumber() string {
+// 整数または浮動小数点数のリテラルを読み込み、トークンの種類とリテラルを返す
+// 整数は10進数のほか "0x1F", "0o17", "0b101" の形式を受け付け、数字の間は'_'で区切れる
+// 浮動小数点数は "1.5", "1e9", "1.5e-3" の形式で、"1." や ".5" のように
+// 小数点の片側に数字が無いものは浮動小数点数として扱わない
+func (l *Lexer) readNumber() (token.TokenType, string) {
+	position := l.position
+
+	if l.ch == '0' {
+		switch l.peekChar() {
+		case 'x', 'X':
+			l.readPrefixedInteger("hexadecimal", isHexDigit)
+			return token.INT, l.input[position:l.position]
+		case 'o', 'O':
+			l.readPrefixedInteger("octal", isOctalDigit)
+			return token.INT, l.input[position:l.position]
+		case 'b', 'B':
+			l.readPrefixedInteger("binary", isBinaryDigit)
+			return token.INT, l.input[position:l.position]
+		}
+	}
+
+	var tokenType token.TokenType = token.INT
+
+	start := l.pos()
+	l.readDigits(isDigit, false)
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
+		l.readChar()
+		l.readDigits(isDigit, false)
+	}
+	if l.ch == 'e' || l.ch == 'E' {
+		tokenType = token.FLOAT
+		exponent := l.pos()
+		l.readChar()
+		if l.ch == '+' || l.ch == '-' {
+			l.readChar()
+		}
+		if l.readDigits(isDigit, false) == 0 {
+			l.error(exponent, "exponent has no digits")
+		}
+	}
+	// 017を8進数と誤解しないよう、先頭が0の10進整数は受け付けない (浮動小数点数の01.5は許可する)
+	literal := l.input[position:l.position]
+	if tokenType == token.INT && len(literal) > 1 && literal[0] == '0' {
+		l.error(start, "leading zeros in decimal integer literals are not permitted; use an 0o prefix for octal integers")
+	}
+	return tokenType, literal
+}
+
+// "0x" などの接頭辞付きの整数を読み込む。呼び出し時点でl.chは先頭の'0'を指す
+func (l *Lexer) readPrefixedInteger(name string, isValid func(rune) bool) {
+	start := l.pos()
+	l.readChar() // '0'
+	l.readChar() // 'x', 'o', 'b'
+
+	if l.readDigits(isValid, true) == 0 {
+		l.error(start, name+" literal has no digits")
+	}
+	if isDigit(l.ch) {
+		l.error(l.pos(), fmt.Sprintf("invalid digit %q in %s literal", l.ch, name))
+		l.readDigits(isDigit, true)
+	}
+}
+
+// 数字と区切りの'_'を読み込み、読み込んだ数字の数を返す
+// '_'は数字同士の間 (afterPrefixがtrueの場合は接頭辞の直後も) にのみ置ける
+func (l *Lexer) readDigits(isValid func(rune) bool, afterPrefix bool) int {
+	digits := 0
+	reported := false
+
+	for isValid(l.ch) || l.ch == '_' {
+		if l.ch == '_' {
+			separates := (digits > 0 || afterPrefix) && isValid(l.peekChar())
+			if !separates && !reported {
+				l.error(l.pos(), "'_' must separate successive digits")
+				reported = true
+			}
+		} else {
+			digits++
+		}
+		l.readChar()
+	}
+	return digits
+}
+
+// '"'で囲まれた文字列を読み込み、エスケープシーケンスを展開した値を返す
+// 呼び出し時点でl.chは開始の'"'、終了時点でl.chは終了の'"'を指す
+func (l *Lexer) readString() string {
+	start := l.pos()
+	var out strings.Builder
+
+	for {
+		l.readChar()
+		switch {
+		case l.ch == '"':
+			return out.String()
+		case l.ch == 0 && l.position >= len(l.input):
+			l.error(start, "unterminated string literal")
+			return out.String()
+		case l.ch == '\\':
+			l.readEscape(&out)
+		default:
+			// 不正なUTF-8のバイトも置き換えずにそのまま残す
+			out.WriteString(l.input[l.position:l.readPosition])
+		}
+	}
+}
+
+// '\\'に続くエスケープシーケンスを読み込み、展開した文字をoutに書き込む
+func (l *Lexer) readEscape(out *strings.Builder) {
+	pos := l.pos()
+
+	switch l.peekChar() {
+	case 'n':
+		out.WriteByte('\n')
+	case 't':
+		out.WriteByte('\t')
+	case '"', '\\':
+		out.WriteRune(l.peekChar())
+	case 'u':
+		l.readChar()
+		l.readUnicodeEscape(out, pos)
+		return
+	case 0:
+		return // 終端は呼び出し元で未終了の文字列として扱う
+	default:
+		l.error(pos, "unknown escape sequence \\"+string(l.peekChar()))
+	}
+	l.readChar()
+}
+
+// \u{XXXX} 形式のエスケープを読み込む。呼び出し時点でl.chは'u'を指す
+func (l *Lexer) readUnicodeEscape(out *strings.Builder, pos token.Position) {
+	if l.peekChar() != '{' {
+		l.error(pos, `invalid unicode escape: expected "{" after \u`)
+		return
+	}
+	l.readChar()
+
+	digitsStart := l.readPosition
+	for isHexDigit(l.peekChar()) {
+		l.readChar()
+	}
+	digits := l.input[digitsStart:l.readPosition]
+
+	if l.peekChar() != '}' || len(digits) == 0 || len(digits) > 6 {
+		l.error(pos, "invalid unicode escape: expected 1 to 6 hex digits followed by \"}\"")
+		return
+	}
+	l.readChar()
+
+	code, _ := strconv.ParseUint(digits, 16, 32)
+	if !utf8.ValidRune(rune(code)) {
+		l.error(pos, "invalid unicode code point U+"+strings.ToUpper(digits))
+		return
+	}
+	out.WriteRune(rune(code))
+}
+
+// "// ..." (行末まで) または "/* ... */" のコメントを読み込み、区切りを含めた文字列を返す
+// 終了時点でl.chはコメントの直後の文字を指す
+func (l *Lexer) readComment() string {
+	start := l.pos()
 	position := l.position
-	for isDigit(l.ch) {
+
+	if l.peekChar() == '/' {
+		for l.ch != '\n' && l.position < len(l.input) {
+			l.readChar()
+		}
+		return l.input[position:l.position]
+	}
+
+	l.readChar() // '/'
+	l.readChar() // '*'
+	for !(l.ch == '*' && l.peekChar() == '/') {
+		if l.position >= len(l.input) {
+			l.error(start, "unterminated block comment")
+			return l.input[position:]
+		}
 		l.readChar()
 	}
+	l.readChar() // '*'
+	l.readChar() // '/'
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) peekChar() byte {
+func (l *Lexer) peekChar() rune {
 	if l.readPosition >= len(l.input) {
 		return 0
 	} else {
-		return l.input[l.readPosition]
+		ch, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+		return ch
 	}
 }
 
+// 現在検査中の文字の位置を返す。Offsetはバイト単位、Columnは文字 (rune) 単位で数える
+func (l *Lexer) pos() token.Position {
+	offset := l.position
+	if offset > len(l.input) {
+		offset = len(l.input) // 終端に到達した後も位置はEOFに留める
+	}
+	return token.Position{
+		Filename: l.filename,
+		Offset:   offset,
+		Line:     l.line,
+		Column:   l.column,
+	}
+}
+
+// トークンに開始位置と終了位置(現在位置)を設定する
+func (l *Lexer) withPosition(tok token.Token, start token.Position) token.Token {
+	tok.Pos = start
+	tok.End = l.pos()
+	return tok
+}
+
 func (l *Lexer) NextToken() token.Token {
 	var tok token.Token
 
 	l.skipWhitespace()
+	for l.ch == '/' && (l.peekChar() == '/' || l.peekChar() == '*') {
+		start := l.pos()
+		comment := l.readComment()
+		if l.emitComments {
+			tok = token.Token{Type: token.COMMENT, Literal: comment}
+			return l.withPosition(tok, start)
+		}
+		l.skipWhitespace()
+	}
+	start := l.pos()
 
 	switch l.ch {
 	case '=':
@@ -70,9 +332,9 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.ASSIGN, l.ch)
 		}
 	case '+':
-		tok = newToken(token.PLUS, l.ch)
+		tok = l.readOperator(token.PLUS, token.PLUS_ASSIGN)
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		tok = l.readOperator(token.MINUS, token.MINUS_ASSIGN)
 	case '!':
 		if l.peekChar() == '=' {
 			ch := l.ch
@@ -86,17 +348,54 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.BANG, l.ch)
 		}
 	case '*':
-		tok = newToken(token.ASTERISK, l.ch)
+		tok = l.readOperator(token.ASTERISK, token.ASTERISK_ASSIGN)
 	case '/':
-		tok = newToken(token.SLASH, l.ch)
+		tok = l.readOperator(token.SLASH, token.SLASH_ASSIGN)
+	case '%':
+		tok = newToken(token.PERCENT, l.ch)
 	case '<':
-		tok = newToken(token.LT, l.ch)
+		if l.peekChar() == '=' {
+			l.readChar()
+			tok = token.Token{Type: token.LT_EQ, Literal: "<="}
+		} else {
+			tok = newToken(token.LT, l.ch)
+		}
 	case '>':
-		tok = newToken(token.GT, l.ch)
+		if l.peekChar() == '=' {
+			l.readChar()
+			tok = token.Token{Type: token.GT_EQ, Literal: ">="}
+		} else {
+			tok = newToken(token.GT, l.ch)
+		}
+	case '?':
+		if l.peekChar() == '?' {
+			l.readChar()
+			tok = token.Token{Type: token.NULLISH, Literal: "??"}
+		} else {
+			tok = newToken(token.QUESTION, l.ch)
+		}
+	case '&':
+		// 単独の'&'は演算子ではない
+		if l.peekChar() == '&' {
+			l.readChar()
+			tok = token.Token{Type: token.AND, Literal: "&&"}
+		} else {
+			tok = newToken(token.ILLEGAL, l.ch)
+		}
+	case '|':
+		// 単独の'|'は演算子ではない
+		if l.peekChar() == '|' {
+			l.readChar()
+			tok = token.Token{Type: token.OR, Literal: "||"}
+		} else {
+			tok = newToken(token.ILLEGAL, l.ch)
+		}
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
 	case ';':
 		tok = newToken(token.SEMICOLON, l.ch)
+	case ':':
+		tok = newToken(token.COLON, l.ch)
 	case '(':
 		tok = newToken(token.LPAREN, l.ch)
 	case ')':
@@ -105,6 +404,13 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
 		tok = newToken(token.RBRACE, l.ch)
+	case '[':
+		tok = newToken(token.LBRACKET, l.ch)
+	case ']':
+		tok = newToken(token.RBRACKET, l.ch)
+	case '"':
+		tok.Type = token.STRING
+		tok.Literal = l.readString()
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
@@ -112,18 +418,28 @@ func (l *Lexer) NextToken() token.Token {
 		if isLetter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
-			return tok
+			return l.withPosition(tok, start)
 		} else if isDigit(l.ch) {
-			tok.Literal = l.readNumber()
-			tok.Type = token.INT
-			return tok
+			tok.Type, tok.Literal = l.readNumber()
+			return l.withPosition(tok, start)
 		} else {
-			tok = newToken(token.ILLEGAL, l.ch)
+			// 不正なUTF-8のバイトも元の入力のまま返す
+			tok = token.Token{Type: token.ILLEGAL, Literal: l.input[l.position:l.readPosition]}
 		}
 	}
 
 	l.readChar()
-	return tok
+	return l.withPosition(tok, start)
+}
+
+// 1文字の演算子か、'='が続く場合は複合代入演算子のトークンを返す
+func (l *Lexer) readOperator(single, withAssign token.TokenType) token.Token {
+	if l.peekChar() == '=' {
+		ch := l.ch
+		l.readChar()
+		return token.Token{Type: withAssign, Literal: string(ch) + "="}
+	}
+	return newToken(single, l.ch)
 }
 
 func (l *Lexer) skipWhitespace() {
@@ -132,17 +448,32 @@ func (l *Lexer) skipWhitespace() {
 	}
 }
 
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{
 		Type:    tokenType,
 		Literal: string(ch),
 	}
 }
 
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+// 識別子の先頭に使える文字かどうか。日本語などUnicodeの文字も受け付ける
+func isLetter(ch rune) bool {
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_' ||
+		ch >= utf8.RuneSelf && unicode.IsLetter(ch)
 }
 
-func isDigit(ch byte) bool {
+// 数値リテラルの数字はASCIIの0-9のみ
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
+
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
+}
+
+func isOctalDigit(ch rune) bool {
+	return '0' <= ch && ch <= '7'
+}
+
+func isBinaryDigit(ch rune) bool {
+	return ch == '0' || ch == '1'
+}
@@ -1,6 +1,7 @@
 package lexer_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/mahiro72/monkey-lang/lexer"
@@ -166,6 +167,177 @@ func TestNextToken(t *testing.T) {
 				{Type: token.EOF, Literal: ""},
 			},
 		},
+		{
+			name:  "success: 文字列",
+			input: `"foobar" "foo bar" "a\n\t\"\\b" "\u{3042}\u{1F412}"`,
+			expectedTokens: []token.Token{
+				{Type: token.STRING, Literal: "foobar"},
+				{Type: token.STRING, Literal: "foo bar"},
+				{Type: token.STRING, Literal: "a\n\t\"\\b"},
+				{Type: token.STRING, Literal: "あ🐒"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			name:  "success: 配列",
+			input: `[1, 2];`,
+			expectedTokens: []token.Token{
+				{Type: token.LBRACKET, Literal: "["},
+				{Type: token.INT, Literal: "1"},
+				{Type: token.COMMA, Literal: ","},
+				{Type: token.INT, Literal: "2"},
+				{Type: token.RBRACKET, Literal: "]"},
+				{Type: token.SEMICOLON, Literal: ";"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			name:  "success: ハッシュ",
+			input: `{"foo": "bar"}`,
+			expectedTokens: []token.Token{
+				{Type: token.LBRACE, Literal: "{"},
+				{Type: token.STRING, Literal: "foo"},
+				{Type: token.COLON, Literal: ":"},
+				{Type: token.STRING, Literal: "bar"},
+				{Type: token.RBRACE, Literal: "}"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			name:  "success: 浮動小数点数",
+			input: `3.14 1e9 2.5E-3 6e+2 10`,
+			expectedTokens: []token.Token{
+				{Type: token.FLOAT, Literal: "3.14"},
+				{Type: token.FLOAT, Literal: "1e9"},
+				{Type: token.FLOAT, Literal: "2.5E-3"},
+				{Type: token.FLOAT, Literal: "6e+2"},
+				{Type: token.INT, Literal: "10"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			name:  "success: 小数点の片側に数字が無いものは浮動小数点数にしない",
+			input: `1. .5`,
+			expectedTokens: []token.Token{
+				{Type: token.INT, Literal: "1"},
+				{Type: token.ILLEGAL, Literal: "."},
+				{Type: token.ILLEGAL, Literal: "."},
+				{Type: token.INT, Literal: "5"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			name:  "success: 接頭辞付きの整数と区切り文字",
+			input: `0x1F 0XaB 0o17 0b1010 1_000_000 0x_FF 1_0.5e1_0`,
+			expectedTokens: []token.Token{
+				{Type: token.INT, Literal: "0x1F"},
+				{Type: token.INT, Literal: "0XaB"},
+				{Type: token.INT, Literal: "0o17"},
+				{Type: token.INT, Literal: "0b1010"},
+				{Type: token.INT, Literal: "1_000_000"},
+				{Type: token.INT, Literal: "0x_FF"},
+				{Type: token.FLOAT, Literal: "1_0.5e1_0"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			name:  "success: 代入演算子",
+			input: `x = 1; x += 2; x -= 3; x *= 4; x /= 5; x == 6`,
+			expectedTokens: []token.Token{
+				{Type: token.IDENT, Literal: "x"},
+				{Type: token.ASSIGN, Literal: "="},
+				{Type: token.INT, Literal: "1"},
+				{Type: token.SEMICOLON, Literal: ";"},
+				{Type: token.IDENT, Literal: "x"},
+				{Type: token.PLUS_ASSIGN, Literal: "+="},
+				{Type: token.INT, Literal: "2"},
+				{Type: token.SEMICOLON, Literal: ";"},
+				{Type: token.IDENT, Literal: "x"},
+				{Type: token.MINUS_ASSIGN, Literal: "-="},
+				{Type: token.INT, Literal: "3"},
+				{Type: token.SEMICOLON, Literal: ";"},
+				{Type: token.IDENT, Literal: "x"},
+				{Type: token.ASTERISK_ASSIGN, Literal: "*="},
+				{Type: token.INT, Literal: "4"},
+				{Type: token.SEMICOLON, Literal: ";"},
+				{Type: token.IDENT, Literal: "x"},
+				{Type: token.SLASH_ASSIGN, Literal: "/="},
+				{Type: token.INT, Literal: "5"},
+				{Type: token.SEMICOLON, Literal: ";"},
+				{Type: token.IDENT, Literal: "x"},
+				{Type: token.EQ, Literal: "=="},
+				{Type: token.INT, Literal: "6"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			name:  "success: 条件演算子とnull合体演算子",
+			input: `a ? b : c ?? d`,
+			expectedTokens: []token.Token{
+				{Type: token.IDENT, Literal: "a"},
+				{Type: token.QUESTION, Literal: "?"},
+				{Type: token.IDENT, Literal: "b"},
+				{Type: token.COLON, Literal: ":"},
+				{Type: token.IDENT, Literal: "c"},
+				{Type: token.NULLISH, Literal: "??"},
+				{Type: token.IDENT, Literal: "d"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			name:  "success: ループとマクロのキーワード",
+			input: `while for in break continue macro inside`,
+			expectedTokens: []token.Token{
+				{Type: token.WHILE, Literal: "while"},
+				{Type: token.FOR, Literal: "for"},
+				{Type: token.IN, Literal: "in"},
+				{Type: token.BREAK, Literal: "break"},
+				{Type: token.CONTINUE, Literal: "continue"},
+				{Type: token.MACRO, Literal: "macro"},
+				{Type: token.IDENT, Literal: "inside"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			name:  "success: 比較演算子と論理演算子",
+			input: `a <= b >= c && d || e % 2 < 1 & |`,
+			expectedTokens: []token.Token{
+				{Type: token.IDENT, Literal: "a"},
+				{Type: token.LT_EQ, Literal: "<="},
+				{Type: token.IDENT, Literal: "b"},
+				{Type: token.GT_EQ, Literal: ">="},
+				{Type: token.IDENT, Literal: "c"},
+				{Type: token.AND, Literal: "&&"},
+				{Type: token.IDENT, Literal: "d"},
+				{Type: token.OR, Literal: "||"},
+				{Type: token.IDENT, Literal: "e"},
+				{Type: token.PERCENT, Literal: "%"},
+				{Type: token.INT, Literal: "2"},
+				{Type: token.LT, Literal: "<"},
+				{Type: token.INT, Literal: "1"},
+				{Type: token.ILLEGAL, Literal: "&"},
+				{Type: token.ILLEGAL, Literal: "|"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			name: "success: コメントは読み飛ばす",
+			input: `// 先頭のコメント
+				let x = 1; // 行末のコメント
+				/* ブロック
+				   コメント */ x /**/ / 2 //`,
+			expectedTokens: []token.Token{
+				{Type: token.LET, Literal: "let"},
+				{Type: token.IDENT, Literal: "x"},
+				{Type: token.ASSIGN, Literal: "="},
+				{Type: token.INT, Literal: "1"},
+				{Type: token.SEMICOLON, Literal: ";"},
+				{Type: token.IDENT, Literal: "x"},
+				{Type: token.SLASH, Literal: "/"},
+				{Type: token.INT, Literal: "2"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -179,7 +351,261 @@ func TestNextToken(t *testing.T) {
 					break // 終端にきたら終了
 				}
 			}
+			testingHelper.AssertEqual(t, tt.expectedTokens, tokens, testingHelper.IgnorePosition)
+		})
+	}
+}
+
+func TestNextTokenPosition(t *testing.T) {
+	tests := []struct {
+		name           string
+		filename       string
+		input          string
+		expectedTokens []token.Token
+	}{
+		{
+			name:  "success: 1行の位置",
+			input: `let x = 10;`,
+			expectedTokens: []token.Token{
+				{Type: token.LET, Literal: "let", Pos: token.Position{Offset: 0, Line: 1, Column: 1}, End: token.Position{Offset: 3, Line: 1, Column: 4}},
+				{Type: token.IDENT, Literal: "x", Pos: token.Position{Offset: 4, Line: 1, Column: 5}, End: token.Position{Offset: 5, Line: 1, Column: 6}},
+				{Type: token.ASSIGN, Literal: "=", Pos: token.Position{Offset: 6, Line: 1, Column: 7}, End: token.Position{Offset: 7, Line: 1, Column: 8}},
+				{Type: token.INT, Literal: "10", Pos: token.Position{Offset: 8, Line: 1, Column: 9}, End: token.Position{Offset: 10, Line: 1, Column: 11}},
+				{Type: token.SEMICOLON, Literal: ";", Pos: token.Position{Offset: 10, Line: 1, Column: 11}, End: token.Position{Offset: 11, Line: 1, Column: 12}},
+				{Type: token.EOF, Literal: "", Pos: token.Position{Offset: 11, Line: 1, Column: 12}, End: token.Position{Offset: 11, Line: 1, Column: 12}},
+			},
+		},
+		{
+			name:     "success: 複数行とファイル名",
+			filename: "main.monkey",
+			input:    "x ==\n\ty\n",
+			expectedTokens: []token.Token{
+				{Type: token.IDENT, Literal: "x", Pos: token.Position{Filename: "main.monkey", Offset: 0, Line: 1, Column: 1}, End: token.Position{Filename: "main.monkey", Offset: 1, Line: 1, Column: 2}},
+				{Type: token.EQ, Literal: "==", Pos: token.Position{Filename: "main.monkey", Offset: 2, Line: 1, Column: 3}, End: token.Position{Filename: "main.monkey", Offset: 4, Line: 1, Column: 5}},
+				{Type: token.IDENT, Literal: "y", Pos: token.Position{Filename: "main.monkey", Offset: 6, Line: 2, Column: 2}, End: token.Position{Filename: "main.monkey", Offset: 7, Line: 2, Column: 3}},
+				{Type: token.EOF, Literal: "", Pos: token.Position{Filename: "main.monkey", Offset: 8, Line: 3, Column: 1}, End: token.Position{Filename: "main.monkey", Offset: 8, Line: 3, Column: 1}},
+			},
+		},
+		{
+			name:  "success: マルチバイト文字はOffsetをバイト、Columnを文字で数える",
+			input: `合計 = "円";`,
+			expectedTokens: []token.Token{
+				{Type: token.IDENT, Literal: "合計", Pos: token.Position{Offset: 0, Line: 1, Column: 1}, End: token.Position{Offset: 6, Line: 1, Column: 3}},
+				{Type: token.ASSIGN, Literal: "=", Pos: token.Position{Offset: 7, Line: 1, Column: 4}, End: token.Position{Offset: 8, Line: 1, Column: 5}},
+				{Type: token.STRING, Literal: "円", Pos: token.Position{Offset: 9, Line: 1, Column: 6}, End: token.Position{Offset: 14, Line: 1, Column: 9}},
+				{Type: token.SEMICOLON, Literal: ";", Pos: token.Position{Offset: 14, Line: 1, Column: 9}, End: token.Position{Offset: 15, Line: 1, Column: 10}},
+				{Type: token.EOF, Literal: "", Pos: token.Position{Offset: 15, Line: 1, Column: 10}, End: token.Position{Offset: 15, Line: 1, Column: 10}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.NewWithFilename(tt.filename, tt.input)
+			var tokens []token.Token
+			for {
+				tok := l.NextToken()
+				tokens = append(tokens, tok)
+				if tok.Type == token.EOF {
+					break
+				}
+			}
 			testingHelper.AssertEqual(t, tt.expectedTokens, tokens)
 		})
 	}
 }
+
+// 1行が長くても列番号は正しく数えられ、字句解析は行の長さに比例した時間で終わる
+func TestNextTokenLongLine(t *testing.T) {
+	const n = 100000
+	input := "let a = [" + strings.Repeat("1,", n) + "];"
+
+	l := lexer.New(input)
+	var last token.Token
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		last = tok
+	}
+
+	testingHelper.AssertEqual(t, token.Token{
+		Type:    token.SEMICOLON,
+		Literal: ";",
+		Pos:     token.Position{Offset: len(input) - 1, Line: 1, Column: len(input)},
+		End:     token.Position{Offset: len(input), Line: 1, Column: len(input) + 1},
+	}, last)
+}
+
+func TestNextTokenErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedErrors []string
+	}{
+		{
+			name:           "failure: 閉じられていない文字列",
+			input:          `let s = "foo`,
+			expectedErrors: []string{"1:9: unterminated string literal"},
+		},
+		{
+			name:           "failure: 指数部の数字が無い浮動小数点数",
+			input:          "x = 1.5e+;",
+			expectedErrors: []string{"1:8: exponent has no digits"},
+		},
+		{
+			name:  "failure: 不正な整数リテラル",
+			input: "0x; 0b; 1__0; 2_; 0b102; 0o8",
+			expectedErrors: []string{
+				"1:1: hexadecimal literal has no digits",
+				"1:5: binary literal has no digits",
+				"1:10: '_' must separate successive digits",
+				"1:16: '_' must separate successive digits",
+				"1:23: invalid digit '2' in binary literal",
+				"1:26: octal literal has no digits",
+				"1:28: invalid digit '8' in octal literal",
+			},
+		},
+		{
+			name:  "failure: 先頭が0の10進整数",
+			input: "017; 0_1; 08; 00; 0; 01.5; 0e1",
+			expectedErrors: []string{
+				"1:1: leading zeros in decimal integer literals are not permitted; use an 0o prefix for octal integers",
+				"1:6: leading zeros in decimal integer literals are not permitted; use an 0o prefix for octal integers",
+				"1:11: leading zeros in decimal integer literals are not permitted; use an 0o prefix for octal integers",
+				"1:15: leading zeros in decimal integer literals are not permitted; use an 0o prefix for octal integers",
+			},
+		},
+		{
+			name:           "failure: 閉じられていないブロックコメント",
+			input:          "x /* comment",
+			expectedErrors: []string{"1:3: unterminated block comment"},
+		},
+		{
+			name:           "failure: 未知のエスケープシーケンス",
+			input:          `"a\qb"`,
+			expectedErrors: []string{"1:3: unknown escape sequence \\q"},
+		},
+		{
+			name:  "failure: 不正なunicodeエスケープ",
+			input: `"\u0041" "\u{}" "\u{110000}"`,
+			expectedErrors: []string{
+				`1:2: invalid unicode escape: expected "{" after \u`,
+				`1:11: invalid unicode escape: expected 1 to 6 hex digits followed by "}"`,
+				"1:18: invalid unicode code point U+110000",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			var errors []string
+			l.SetErrorHandler(func(pos token.Position, msg string) {
+				errors = append(errors, pos.String()+": "+msg)
+			})
+			for l.NextToken().Type != token.EOF {
+			}
+			testingHelper.AssertEqual(t, tt.expectedErrors, errors)
+		})
+	}
+}
+
+func TestNextTokenEmitComments(t *testing.T) {
+	input := "// head\nlet x = 1; /* a\nb */ x"
+	expectedTokens := []token.Token{
+		{Type: token.COMMENT, Literal: "// head", Pos: token.Position{Offset: 0, Line: 1, Column: 1}, End: token.Position{Offset: 7, Line: 1, Column: 8}},
+		{Type: token.LET, Literal: "let", Pos: token.Position{Offset: 8, Line: 2, Column: 1}, End: token.Position{Offset: 11, Line: 2, Column: 4}},
+		{Type: token.IDENT, Literal: "x", Pos: token.Position{Offset: 12, Line: 2, Column: 5}, End: token.Position{Offset: 13, Line: 2, Column: 6}},
+		{Type: token.ASSIGN, Literal: "=", Pos: token.Position{Offset: 14, Line: 2, Column: 7}, End: token.Position{Offset: 15, Line: 2, Column: 8}},
+		{Type: token.INT, Literal: "1", Pos: token.Position{Offset: 16, Line: 2, Column: 9}, End: token.Position{Offset: 17, Line: 2, Column: 10}},
+		{Type: token.SEMICOLON, Literal: ";", Pos: token.Position{Offset: 17, Line: 2, Column: 10}, End: token.Position{Offset: 18, Line: 2, Column: 11}},
+		{Type: token.COMMENT, Literal: "/* a\nb */", Pos: token.Position{Offset: 19, Line: 2, Column: 12}, End: token.Position{Offset: 28, Line: 3, Column: 5}},
+		{Type: token.IDENT, Literal: "x", Pos: token.Position{Offset: 29, Line: 3, Column: 6}, End: token.Position{Offset: 30, Line: 3, Column: 7}},
+		{Type: token.EOF, Literal: "", Pos: token.Position{Offset: 30, Line: 3, Column: 7}, End: token.Position{Offset: 30, Line: 3, Column: 7}},
+	}
+
+	l := lexer.New(input)
+	l.SetEmitComments(true)
+	var tokens []token.Token
+	for {
+		tok := l.NextToken()
+		tokens = append(tokens, tok)
+		if tok.Type == token.EOF {
+			break
+		}
+	}
+	testingHelper.AssertEqual(t, expectedTokens, tokens)
+}
+
+func TestNextTokenUnicode(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedTokens []token.Token
+	}{
+		{
+			name:  "success: 日本語やアクセント付きの識別子",
+			input: `let 合計 = café + 値1;`,
+			expectedTokens: []token.Token{
+				{Type: token.LET, Literal: "let"},
+				{Type: token.IDENT, Literal: "合計"},
+				{Type: token.ASSIGN, Literal: "="},
+				{Type: token.IDENT, Literal: "café"},
+				{Type: token.PLUS, Literal: "+"},
+				{Type: token.IDENT, Literal: "値1"},
+				{Type: token.SEMICOLON, Literal: ";"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			name:  "success: 識別子の先頭の数字は数値として読む",
+			input: `1番目`,
+			expectedTokens: []token.Token{
+				{Type: token.INT, Literal: "1"},
+				{Type: token.IDENT, Literal: "番目"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			name:  "success: 文字列中のマルチバイト文字は分割されない",
+			input: `"こんにちは、世界\t🌏"`,
+			expectedTokens: []token.Token{
+				{Type: token.STRING, Literal: "こんにちは、世界\t🌏"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			name:  "success: 文字以外の記号は1文字のILLEGALになる",
+			input: "x → y",
+			expectedTokens: []token.Token{
+				{Type: token.IDENT, Literal: "x"},
+				{Type: token.ILLEGAL, Literal: "→"},
+				{Type: token.IDENT, Literal: "y"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			name:  "success: 不正なUTF-8のバイトはそのままILLEGALになる",
+			input: "a\xffb",
+			expectedTokens: []token.Token{
+				{Type: token.IDENT, Literal: "a"},
+				{Type: token.ILLEGAL, Literal: "\xff"},
+				{Type: token.IDENT, Literal: "b"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			var tokens []token.Token
+			for {
+				tok := l.NextToken()
+				tokens = append(tokens, tok)
+				if tok.Type == token.EOF {
+					break
+				}
+			}
+			testingHelper.AssertEqual(t, tt.expectedTokens, tokens, testingHelper.IgnorePosition)
+		})
+	}
+}
@@ -9,6 +9,15 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "fmt":
+			os.Exit(runFmt(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+		case "ast":
+			os.Exit(runAST(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+		}
+	}
+
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
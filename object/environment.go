@@ -14,7 +14,7 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 
 type Environment struct {
 	store map[string]Object //名前に関連づけられた値を記録
-	outer *Environment //外側の環境への参照
+	outer *Environment      //外側の環境への参照
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
@@ -29,3 +29,15 @@ func (e *Environment) Set(name string, val Object) Object {
 	e.store[name] = val
 	return val
 }
+
+// 既存の束縛を更新する。Setと異なり外側の環境も辿り、名前が最初に見つかった環境の値を書き換える
+// どの環境にも束縛が無い場合はfalseを返し、何も変更しない
+func (e *Environment) Assign(name string, val Object) (Object, bool) {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			env.store[name] = val
+			return val, true
+		}
+	}
+	return nil, false
+}
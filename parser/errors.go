@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mahiro72/monkey-lang/token"
+)
+
+// 構文解析中に見つかったエラー
+type ParseError struct {
+	Pos      token.Position    // エラーの位置
+	Expected []token.TokenType // 期待していたトークンの種類 (無い場合はnil)
+	Actual   token.Token       // 実際に現れたトークン
+	Msg      string            // 位置を含まないメッセージ
+}
+
+// "line:column: message" 形式で返す
+func (e *ParseError) Error() string {
+	if e.Pos.IsValid() {
+		return e.Pos.String() + ": " + e.Msg
+	}
+	return e.Msg
+}
+
+// 構文解析エラーの一覧
+type ErrorList []*ParseError
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// エラーが無ければnilを返す
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+// 位置を含まないメッセージの一覧を返す
+func (l ErrorList) Messages() []string {
+	msgs := make([]string, len(l))
+	for i, e := range l {
+		msgs[i] = e.Msg
+	}
+	return msgs
+}
+
+// エラーのあった行を表示し、その下の列に'^'を置いた2行の文字列を返す
+// sourceはエラーの位置を計算したソース全体
+func (e *ParseError) Caret(source string) string {
+	if !e.Pos.IsValid() {
+		return ""
+	}
+
+	if e.Pos.Offset > len(source) {
+		return ""
+	}
+	lineStart := strings.LastIndexByte(source[:e.Pos.Offset], '\n') + 1
+	line := source[lineStart:]
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+
+	var out strings.Builder
+	out.WriteString(line)
+	out.WriteString("\n")
+	// 端末での表示幅に合わせて、エラー位置までの文字ごとにその幅の数だけ空白を置く
+	for _, ch := range source[lineStart:e.Pos.Offset] {
+		// タブはそのまま残して表示上の列を揃える
+		if ch == '\t' {
+			out.WriteByte('\t')
+		} else {
+			out.WriteString(strings.Repeat(" ", runeWidth(ch)))
+		}
+	}
+	out.WriteString("^")
+	return out.String()
+}
+
+// 文字の端末での表示幅。東アジアの文字幅 (UAX #11) がWideまたはFullwidthの文字は2、結合文字は0、それ以外は1とする
+func runeWidth(ch rune) int {
+	if unicode.In(ch, unicode.Mn, unicode.Me) {
+		return 0
+	}
+	i := sort.Search(len(wideRanges), func(i int) bool { return wideRanges[i].last >= ch })
+	if i < len(wideRanges) && wideRanges[i].first <= ch {
+		return 2
+	}
+	return 1
+}
+
+// 表示幅が2の文字の範囲 (EastAsianWidth.txtのW, Fの主なもの)。firstの昇順に並べる
+var wideRanges = []struct{ first, last rune }{
+	{0x1100, 0x115F}, // ハングル字母
+	{0x231A, 0x231B}, // 絵文字の時計
+	{0x2329, 0x232A}, // 山括弧
+	{0x23E9, 0x23EC}, // 絵文字の記号
+	{0x23F0, 0x23F0},
+	{0x23F3, 0x23F3},
+	{0x25FD, 0x25FE},
+	{0x2614, 0x2615},
+	{0x2648, 0x2653}, // 星座
+	{0x267F, 0x267F},
+	{0x2693, 0x2693},
+	{0x26A1, 0x26A1},
+	{0x26AA, 0x26AB},
+	{0x26BD, 0x26BE},
+	{0x26C4, 0x26C5},
+	{0x26CE, 0x26CE},
+	{0x26D4, 0x26D4},
+	{0x26EA, 0x26EA},
+	{0x26F2, 0x26F3},
+	{0x26F5, 0x26F5},
+	{0x26FA, 0x26FA},
+	{0x26FD, 0x26FD},
+	{0x2705, 0x2705},
+	{0x270A, 0x270B},
+	{0x2728, 0x2728},
+	{0x274C, 0x274C},
+	{0x274E, 0x274E},
+	{0x2753, 0x2755},
+	{0x2757, 0x2757},
+	{0x2795, 0x2797},
+	{0x27B0, 0x27B0},
+	{0x27BF, 0x27BF},
+	{0x2B1B, 0x2B1C},
+	{0x2B50, 0x2B50},
+	{0x2B55, 0x2B55},
+	{0x2E80, 0x303E}, // CJKの部首, 記号, 句読点
+	{0x3041, 0x33FF}, // ひらがな, カタカナ, 注音字母, 囲みCJK文字など
+	{0x3400, 0x4DBF}, // CJK統合漢字拡張A
+	{0x4E00, 0x9FFF}, // CJK統合漢字
+	{0xA000, 0xA4CF}, // イ文字
+	{0xA960, 0xA97F}, // ハングル字母拡張A
+	{0xAC00, 0xD7A3}, // ハングル音節
+	{0xF900, 0xFAFF}, // CJK互換漢字
+	{0xFE10, 0xFE19}, // 縦書き用の形
+	{0xFE30, 0xFE6F}, // CJK互換形, 小字形
+	{0xFF00, 0xFF60}, // 全角英数字, 記号
+	{0xFFE0, 0xFFE6}, // 全角の通貨記号など
+	{0x16FE0, 0x16FE4},
+	{0x17000, 0x18CFF}, // 西夏文字
+	{0x1B000, 0x1B2FF}, // かな補助など
+	{0x1F004, 0x1F004},
+	{0x1F0CF, 0x1F0CF},
+	{0x1F18E, 0x1F18E},
+	{0x1F191, 0x1F19A},
+	{0x1F200, 0x1F251}, // 囲み漢字
+	{0x1F300, 0x1F64F}, // 絵文字
+	{0x1F680, 0x1F6FF}, // 交通と地図の記号
+	{0x1F900, 0x1F9FF}, // 補助絵文字
+	{0x20000, 0x2FFFD}, // CJK統合漢字拡張B以降
+	{0x30000, 0x3FFFD},
+}
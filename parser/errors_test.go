@@ -0,0 +1,316 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/mahiro72/monkey-lang/lexer"
+	"github.com/mahiro72/monkey-lang/parser"
+	testingHelper "github.com/mahiro72/monkey-lang/testing"
+	"github.com/mahiro72/monkey-lang/token"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedErrors parser.ErrorList
+	}{
+		{
+			name:  "failure: 期待したトークンと異なる",
+			input: "let x 5;",
+			expectedErrors: parser.ErrorList{
+				{
+					Pos:      token.Position{Offset: 6, Line: 1, Column: 7},
+					Expected: []token.TokenType{token.ASSIGN},
+					Actual: token.Token{
+						Type:    token.INT,
+						Literal: "5",
+						Pos:     token.Position{Offset: 6, Line: 1, Column: 7},
+						End:     token.Position{Offset: 7, Line: 1, Column: 8},
+					},
+					Msg: "expected next token to be =, got INT instead",
+				},
+			},
+		},
+		{
+			name:  "failure: 前置構文解析関数が無い",
+			input: "let x = 1;\n  ) ",
+			expectedErrors: parser.ErrorList{
+				{
+					Pos: token.Position{Offset: 13, Line: 2, Column: 3},
+					Actual: token.Token{
+						Type:    token.RPAREN,
+						Literal: ")",
+						Pos:     token.Position{Offset: 13, Line: 2, Column: 3},
+						End:     token.Position{Offset: 14, Line: 2, Column: 4},
+					},
+					Msg: "no prefix parse function for ) found",
+				},
+			},
+		},
+		{
+			name:  "failure: 字句解析エラー",
+			input: `"abc`,
+			expectedErrors: parser.ErrorList{
+				{
+					Pos: token.Position{Offset: 0, Line: 1, Column: 1},
+					Msg: "unterminated string literal",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+			p.ParseProgram()
+
+			testingHelper.AssertEqual(t, tt.expectedErrors, p.ParseErrors())
+		})
+	}
+}
+
+func TestErrorListError(t *testing.T) {
+	p := parser.New(lexer.New("let = 1;\nlet y 2;"))
+	p.ParseProgram()
+	errs := p.ParseErrors()
+
+	testingHelper.AssertEqual(t, []string{
+		"1:5: expected next token to be IDENT, got = instead",
+		"2:7: expected next token to be =, got INT instead",
+	}, []string{errs[0].Error(), errs[1].Error()})
+	testingHelper.AssertEqual(t, "1:5: expected next token to be IDENT, got = instead (and 1 more errors)", errs.Error())
+	testingHelper.AssertEqual(t, nil, parser.ErrorList{}.Err())
+}
+
+func TestParseErrorCaret(t *testing.T) {
+	source := "let x = 1;\n\tlet y 2;\n"
+	p := parser.New(lexer.New(source))
+	p.ParseProgram()
+	errs := p.ParseErrors()
+
+	testingHelper.AssertEqual(t, 1, len(errs))
+	testingHelper.AssertEqual(t, "\tlet y 2;\n\t      ^", errs[0].Caret(source))
+}
+
+// 列番号は文字単位だが、'^'は端末での表示幅に合わせて置く
+func TestParseErrorCaretMultibyte(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expectedError string
+		expectedCaret string
+	}{
+		{
+			name:          "failure: 日本語の識別子は1文字を2列とする",
+			input:         `let 合計 = (1 + 2;`,
+			expectedError: "1:16: expected next token to be ), got ; instead",
+			expectedCaret: "let 合計 = (1 + 2;\n                 ^",
+		},
+		{
+			name:          "failure: 全角英字",
+			input:         `let Ａ = (1;`,
+			expectedError: "1:11: expected next token to be ), got ; instead",
+			expectedCaret: "let Ａ = (1;\n           ^",
+		},
+		{
+			name:          "failure: 絵文字",
+			input:         `let s = "🐒" + (1;`,
+			expectedError: "1:17: expected next token to be ), got ; instead",
+			expectedCaret: "let s = \"🐒\" + (1;\n                 ^",
+		},
+		{
+			name:          "failure: 結合文字は幅を持たない",
+			input:         "let s = \"e\u0301\" + (1;",
+			expectedError: "1:18: expected next token to be ), got ; instead",
+			expectedCaret: "let s = \"e\u0301\" + (1;\n                ^",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.New(lexer.New(tt.input))
+			p.ParseProgram()
+			errs := p.ParseErrors()
+
+			testingHelper.AssertEqual(t, 1, len(errs))
+			testingHelper.AssertEqual(t, tt.expectedError, errs[0].Error())
+			testingHelper.AssertEqual(t, tt.expectedCaret, errs[0].Caret(tt.input))
+		})
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedString string
+		expectedErrors []string
+	}{
+		{
+			name: "failure: 誤りのある文を飛ばして次の文から再開する",
+			input: `
+				let x = 1;
+				let 2 = y;
+				let z = x + 1;
+			`,
+			expectedString: "let x = 1;let z = (x + 1);",
+			expectedErrors: []string{"3:9: expected next token to be IDENT, got INT instead"},
+		},
+		{
+			name: "failure: セミコロンが無くても文のキーワードで再開する",
+			input: `
+				let a = (1 + 2
+				let b = 3;
+				return a + b
+			`,
+			expectedString: "let b = 3;return (a + b);",
+			expectedErrors: []string{"3:5: expected next token to be ), got LET instead"},
+		},
+		{
+			name: "failure: ブロック内のエラーはブロックの終わりまでで回復する",
+			input: `
+				let f = fn(x) { let = x; x };
+				f(1 2);
+				let ok = true;
+			`,
+			expectedString: "let ok = true;",
+			expectedErrors: []string{
+				"2:25: expected next token to be IDENT, got = instead",
+				"3:9: expected next token to be ), got INT instead",
+			},
+		},
+		{
+			name: "failure: 式の途中の'{'は対応する'}'まで読み飛ばす",
+			input: `
+				let h = {"a" 1, "b": {"c": 2}};
+				h;
+			`,
+			expectedString: "h",
+			expectedErrors: []string{"2:18: expected next token to be :, got INT instead"},
+		},
+		{
+			name: "failure: 次の文の字句解析エラーは前の文に影響しない",
+			input: `
+				let a = 1;
+				"abc
+			`,
+			expectedString: "let a = 1;",
+			expectedErrors: []string{"3:5: unterminated string literal"},
+		},
+		{
+			name: "failure: トップレベルの余分な'}'",
+			input: `
+				} let x = 1;
+			`,
+			expectedString: "let x = 1;",
+			expectedErrors: []string{"2:5: no prefix parse function for } found"},
+		},
+		{
+			name: "failure: 引数のエラーは1件だけ報告する",
+			input: `
+				foo(1, )
+				let z = 3;
+			`,
+			expectedString: "let z = 3;",
+			expectedErrors: []string{"2:12: no prefix parse function for ) found"},
+		},
+		{
+			name: "failure: 配列の要素のエラーは1件だけ報告する",
+			input: `
+				[1, , 2];
+				let z = 3;
+			`,
+			expectedString: "let z = 3;",
+			expectedErrors: []string{"2:9: no prefix parse function for , found"},
+		},
+		{
+			name: "failure: 添字のエラーは1件だけ報告する",
+			input: `
+				a[];
+				let z = 3;
+			`,
+			expectedString: "let z = 3;",
+			expectedErrors: []string{"2:7: no prefix parse function for ] found"},
+		},
+		{
+			name: "failure: ハッシュの値のエラーは1件だけ報告する",
+			input: `
+				{1: };
+				let z = 3;
+			`,
+			expectedString: "let z = 3;",
+			expectedErrors: []string{"2:9: no prefix parse function for } found"},
+		},
+		{
+			name: "failure: ハッシュのキーのエラーは1件だけ報告する",
+			input: `
+				{: 1};
+				let z = 3;
+			`,
+			expectedString: "let z = 3;",
+			expectedErrors: []string{"2:6: no prefix parse function for : found"},
+		},
+		{
+			name: "failure: 条件演算子の部分式のエラーは1件だけ報告する",
+			input: `
+				x ? : 1;
+				let z = 3;
+			`,
+			expectedString: "let z = 3;",
+			expectedErrors: []string{"2:9: no prefix parse function for : found"},
+		},
+		{
+			name: "failure: while文の条件のエラーは1件だけ報告する",
+			input: `
+				while () { 1 }
+				let z = 3;
+			`,
+			expectedString: "let z = 3;",
+			expectedErrors: []string{"2:12: no prefix parse function for ) found"},
+		},
+		{
+			name: "failure: if式の条件のエラーは1件だけ報告する",
+			input: `
+				if () { 1 }
+				let z = 3;
+			`,
+			expectedString: "let z = 3;",
+			expectedErrors: []string{"2:9: no prefix parse function for ) found"},
+		},
+		{
+			name: "failure: 括弧の中のエラーは1件だけ報告する",
+			input: `
+				( );
+				let z = 3;
+			`,
+			expectedString: "let z = 3;",
+			expectedErrors: []string{"2:7: no prefix parse function for ) found"},
+		},
+		{
+			name: "failure: for-in文の繰り返す値のエラーは1件だけ報告する",
+			input: `
+				for (x in ) { 1 }
+				let z = 3;
+			`,
+			expectedString: "let z = 3;",
+			expectedErrors: []string{"2:15: no prefix parse function for ) found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+			program := p.ParseProgram()
+
+			var errors []string
+			for _, e := range p.ParseErrors() {
+				errors = append(errors, e.Error())
+			}
+			testingHelper.AssertEqual(t, tt.expectedErrors, errors)
+			testingHelper.AssertEqual(t, tt.expectedString, program.String())
+		})
+	}
+}
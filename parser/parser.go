@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -12,24 +13,43 @@ import (
 const (
 	_ int = iota
 	LOWEST
+	ASSIGN      // = or +=
+	CONDITIONAL // ? :
+	NULLISH     // ??
+	LOGICAL_OR  // ||
+	LOGICAL_AND // &&
 	EQUALS      // ==
 	LESSGREATER // > or <
 	SUM         //+
-	PRODUCT     //*
+	PRODUCT     //* or %
 	PREFIX      // -X or !X
 	CALL        //myFunction(x)
+	INDEX       //array[index]
 )
 
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.LPAREN:   CALL,
+	token.ASSIGN:          ASSIGN,
+	token.PLUS_ASSIGN:     ASSIGN,
+	token.MINUS_ASSIGN:    ASSIGN,
+	token.ASTERISK_ASSIGN: ASSIGN,
+	token.SLASH_ASSIGN:    ASSIGN,
+	token.QUESTION:        CONDITIONAL,
+	token.NULLISH:         NULLISH,
+	token.OR:              LOGICAL_OR,
+	token.AND:             LOGICAL_AND,
+	token.EQ:              EQUALS,
+	token.NOT_EQ:          EQUALS,
+	token.LT:              LESSGREATER,
+	token.GT:              LESSGREATER,
+	token.LT_EQ:           LESSGREATER,
+	token.GT_EQ:           LESSGREATER,
+	token.PLUS:            SUM,
+	token.MINUS:           SUM,
+	token.SLASH:           PRODUCT,
+	token.ASTERISK:        PRODUCT,
+	token.PERCENT:         PRODUCT,
+	token.LPAREN:          CALL,
+	token.LBRACKET:        INDEX,
 }
 
 type (
@@ -45,14 +65,20 @@ type Parser struct {
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
 
-	errors []string
+	errors        ErrorList
+	pendingErrors ErrorList // peekTokenの字句解析で見つかった、まだ確定していないエラー
+	curTokenBad   bool      // curTokenの字句解析でエラーがあったかどうか
+	blockDepth    int       // 読み込み中のブロックのネストの深さ (エラー回復で使う)
+	loopDepth     int       // 読み込み中のループのネストの深さ (関数の中では0から数え直す)
 }
 
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
 		l:      l,
-		errors: []string{},
+		errors: ErrorList{},
 	}
+	l.SetErrorHandler(p.lexerError)
+
 	// 2つのトークンを読み込む。これによりcurToken, peekTokenのどちらも設定される
 	p.nextToken()
 	p.nextToken()
@@ -61,6 +87,8 @@ func New(l *lexer.Lexer) *Parser {
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
+	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
@@ -68,6 +96,9 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
+	p.registerPrefix(token.MACRO, p.parseMacroLiteral)
+	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
 
 	// 中間演算子用のパース関数
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
@@ -75,16 +106,33 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.MINUS, p.parseInfixExpression)
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
 	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
+	p.registerInfix(token.PERCENT, p.parseInfixExpression)
 	p.registerInfix(token.EQ, p.parseInfixExpression)
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.LT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.GT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.NULLISH, p.parseInfixExpression)
+	p.registerInfix(token.QUESTION, p.parseConditionalExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
+	p.registerInfix(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PLUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.MINUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.ASTERISK_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.SLASH_ASSIGN, p.parseAssignExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
+	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 	return p
 }
 
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
+	// 字句解析エラーは、そのトークンを読み進めた文のエラーとして扱う
+	p.errors = append(p.errors, p.pendingErrors...)
+	p.curTokenBad = len(p.pendingErrors) > 0
+	p.pendingErrors = nil
 	p.peekToken = p.l.NextToken()
 }
 
@@ -93,7 +141,10 @@ func (p *Parser) ParseProgram() *ast.Program {
 	program.Statements = []ast.Statement{}
 
 	for p.curToken.Type != token.EOF {
-		stmt := p.parseStatement()
+		stmt, ok := p.parseStatementOrSync()
+		if !ok {
+			continue
+		}
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
@@ -102,17 +153,208 @@ func (p *Parser) ParseProgram() *ast.Program {
 	return program
 }
 
+// 文を1つ読み込む。エラーがあった場合はその文を捨てて次の文の先頭まで読み飛ばし、falseを返す
+func (p *Parser) parseStatementOrSync() (ast.Statement, bool) {
+	start := p.curToken
+	startBad := p.curTokenBad // 先頭のトークンのエラーは読み進めた時点で記録済み
+	errorCount := len(p.errors)
+
+	stmt := p.parseStatement()
+	if len(p.errors) > errorCount || startBad {
+		p.synchronize(start)
+		return nil, false
+	}
+	return stmt, true
+}
+
+// 次の文の先頭になりうるトークン
+var statementStarts = map[token.TokenType]bool{
+	token.LET:      true,
+	token.RETURN:   true,
+	token.WHILE:    true,
+	token.FOR:      true,
+	token.BREAK:    true,
+	token.CONTINUE: true,
+}
+
+// パニックモードのエラー回復。startから始まった文の残りを読み飛ばし、
+// curTokenを次の文の先頭(';'の次, 文のキーワード)か、囲んでいるブロックの'}'に合わせる
+func (p *Parser) synchronize(start token.Token) {
+	depth := 0 // 読み飛ばしている間に開いた'{'の数
+
+	for !p.curTokenIs(token.EOF) {
+		switch {
+		case p.curTokenIs(token.LBRACE):
+			depth++
+		case p.curTokenIs(token.RBRACE) && depth > 0:
+			depth--
+		case p.curTokenIs(token.RBRACE) && p.blockDepth > 0:
+			return // ブロックの終わりはparseBlockStatementに任せる
+		case p.curTokenIs(token.SEMICOLON) && depth == 0:
+			p.nextToken()
+			return
+		case statementStarts[p.curToken.Type] && depth == 0 && p.curToken.Pos.Offset > start.Pos.Offset:
+			return
+		}
+		p.nextToken()
+	}
+}
+
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.curToken.Type {
 	case token.LET:
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.WHILE:
+		return p.parseWhileStatement()
+	case token.FOR:
+		return p.parseForStatement()
+	case token.BREAK, token.CONTINUE:
+		return p.parseLoopControlStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
 }
 
+// for文の初期化や後処理に書ける、let文または式文を読み込む
+func (p *Parser) parseSimpleStatement() ast.Statement {
+	if p.curTokenIs(token.LET) {
+		return p.parseLetStatement()
+	}
+	return p.parseExpressionStatement()
+}
+
+func (p *Parser) parseWhileStatement() ast.Statement {
+	stmt := &ast.WhileStatement{Token: p.curToken}
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+
+	stmt.Condition = p.parseExpression(LOWEST)
+	if stmt.Condition == nil {
+		return nil // 条件のエラーは報告済み
+	}
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	stmt.Body = p.parseLoopBody()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+// "for (init; condition; post)" と "for (x in iterable)" の2つの形式を読み込む
+func (p *Parser) parseForStatement() ast.Statement {
+	forToken := p.curToken
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+
+	if p.curTokenIs(token.IDENT) && p.peekTokenIs(token.IN) {
+		return p.parseForInStatement(forToken)
+	}
+
+	stmt := &ast.ForStatement{Token: forToken}
+
+	// let文や式文は末尾の';'を読み進めるので、読み進めていなければここで要求する
+	if !p.curTokenIs(token.SEMICOLON) {
+		stmt.Init = p.parseSimpleStatement()
+		if !p.curTokenIs(token.SEMICOLON) && !p.expectPeek(token.SEMICOLON) {
+			return nil
+		}
+	}
+	p.nextToken()
+
+	if !p.curTokenIs(token.SEMICOLON) {
+		stmt.Condition = p.parseExpression(LOWEST)
+		if stmt.Condition == nil {
+			return nil // 条件のエラーは報告済み
+		}
+		if !p.expectPeek(token.SEMICOLON) {
+			return nil
+		}
+	}
+	p.nextToken()
+
+	if !p.curTokenIs(token.RPAREN) {
+		stmt.Post = p.parseSimpleStatement()
+		if !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	stmt.Body = p.parseLoopBody()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+// 呼び出し時点でcurTokenはループ変数を指す
+func (p *Parser) parseForInStatement(forToken token.Token) ast.Statement {
+	stmt := &ast.ForInStatement{Token: forToken}
+	stmt.Variable = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	p.nextToken() // in
+	p.nextToken()
+
+	stmt.Iterable = p.parseExpression(LOWEST)
+	if stmt.Iterable == nil {
+		return nil // 繰り返す値のエラーは報告済み
+	}
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	stmt.Body = p.parseLoopBody()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+// ループ本体のブロックを読み込む。本体の中ではbreak, continueを書ける
+func (p *Parser) parseLoopBody() *ast.BlockStatement {
+	p.loopDepth++
+	defer func() { p.loopDepth-- }()
+	return p.parseBlockStatement()
+}
+
+func (p *Parser) parseLoopControlStatement() ast.Statement {
+	var stmt ast.Statement
+	if p.curTokenIs(token.BREAK) {
+		stmt = &ast.BreakStatement{Token: p.curToken}
+	} else {
+		stmt = &ast.ContinueStatement{Token: p.curToken}
+	}
+
+	if p.loopDepth == 0 {
+		msg := fmt.Sprintf("%s statement outside loop", p.curToken.Literal)
+		p.addError(p.curToken.Pos, nil, p.curToken, msg)
+		return nil
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
 func (p *Parser) parseLetStatement() ast.Statement {
 	stmt := &ast.LetStatement{Token: p.curToken}
 
@@ -129,6 +371,11 @@ func (p *Parser) parseLetStatement() ast.Statement {
 
 	stmt.Value = p.parseExpression(LOWEST)
 
+	// エラーメッセージなどで関数を名前で示せるように、束縛先の名前を記録する
+	if fl, ok := stmt.Value.(*ast.FunctionLiteral); ok {
+		fl.Name = stmt.Name.Value
+	}
+
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
@@ -143,7 +390,7 @@ func (p *Parser) parseReturnStatement() ast.Statement {
 
 	stmt.ReturnValue = p.parseExpression(LOWEST)
 
-	for !p.curTokenIs(token.SEMICOLON) {
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 	return stmt
@@ -184,7 +431,10 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 			return leftExp
 		}
 		p.nextToken()
-		leftExp = infix(leftExp)
+		// 左辺にエラーがあった場合 (nilの場合) も続く演算子は読み進めるが、結果は不完全なのでnilとする
+		if exp := infix(leftExp); leftExp != nil {
+			leftExp = exp
+		}
 	}
 	return leftExp
 }
@@ -200,12 +450,15 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 	p.nextToken()
 
 	expression.Right = p.parseExpression(PREFIX)
+	if expression.Right == nil {
+		return nil // 右辺のエラーは報告済み
+	}
 	return expression
 }
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
 	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	p.addError(p.curToken.Pos, nil, p.curToken, msg)
 }
 
 func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
@@ -220,6 +473,56 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	precedence := p.curPrecedence()
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
+	if expression.Right == nil {
+		return nil // 右辺のエラーは報告済み
+	}
+	return expression
+}
+
+// 条件演算子は右結合で、a ? b : c ? d : e は a ? b : (c ? d : e) となる
+func (p *Parser) parseConditionalExpression(condition ast.Expression) ast.Expression {
+	expression := &ast.ConditionalExpression{Token: p.curToken, Condition: condition}
+
+	p.nextToken()
+	expression.Consequence = p.parseExpression(LOWEST)
+	if expression.Consequence == nil {
+		return nil // 部分式のエラーは報告済み
+	}
+
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+	p.nextToken()
+	expression.Alternative = p.parseExpression(CONDITIONAL - 1)
+	if expression.Alternative == nil {
+		return nil // 部分式のエラーは報告済み
+	}
+	return expression
+}
+
+// 代入は右結合で、a = b = c は a = (b = c) となる
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
+	expression := &ast.AssignExpression{
+		Token:    p.curToken,
+		Target:   target,
+		Operator: p.curToken.Literal,
+	}
+
+	switch target.(type) {
+	case *ast.Identifier, *ast.IndexExpression:
+	case nil:
+		return nil // 左辺のエラーは報告済み
+	default:
+		msg := fmt.Sprintf("cannot assign to %s", target.String())
+		p.addError(p.curToken.Pos, nil, p.curToken, msg)
+		return nil
+	}
+
+	p.nextToken()
+	expression.Value = p.parseExpression(ASSIGN - 1)
+	if expression.Value == nil {
+		return nil
+	}
 	return expression
 }
 
@@ -229,16 +532,48 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 
+	// 017のようにParseIntが8進数として受け付けてしまうものも含め、不正なリテラルは字句解析器が報告済み
+	if p.curTokenBad {
+		return nil
+	}
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		msg := fmt.Sprintf("integer literal %s out of range for int64", p.curToken.Literal)
+		p.addError(p.curToken.Pos, nil, p.curToken, msg)
+		return nil
+	}
 	if err != nil {
 		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.addError(p.curToken.Pos, nil, p.curToken, msg)
+		return nil
+	}
+	lit.Value = value
+	return lit
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.curToken}
+
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil && p.curTokenBad {
+		return nil // 不正なリテラルは字句解析器が報告済み
+	}
+	if err != nil {
+		msg := fmt.Sprintf("could not parse %q as float", p.curToken.Literal)
+		p.addError(p.curToken.Pos, nil, p.curToken, msg)
 		return nil
 	}
 	lit.Value = value
 	return lit
 }
 
+func (p *Parser) parseStringLiteral() ast.Expression {
+	return &ast.StringLiteral{
+		Token: p.curToken,
+		Value: p.curToken.Literal,
+	}
+}
+
 func (p *Parser) parseIdentifier() ast.Expression {
 	// trace(fmt.Sprintf("parseIdentifier: curToken=%s", p.curToken.Literal))
 	// defer untrace("parseIdentifier")
@@ -263,6 +598,9 @@ func (p *Parser) parseGroupedExpression() ast.Expression {
 	p.nextToken()
 
 	exp := p.parseExpression(LOWEST)
+	if exp == nil {
+		return nil // 括弧の中のエラーは報告済み
+	}
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
@@ -280,6 +618,9 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	p.nextToken()
 
 	expression.Condition = p.parseExpression(LOWEST)
+	if expression.Condition == nil {
+		return nil // 条件のエラーは報告済み
+	}
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
@@ -291,6 +632,15 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	if p.peekTokenIs(token.ELSE) {
 		p.nextToken()
 
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+			expression.Alternative = p.parseElseIf()
+			if expression.Alternative == nil {
+				return nil
+			}
+			return expression
+		}
+
 		if !p.expectPeek(token.LBRACE) {
 			return nil
 		}
@@ -300,6 +650,21 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return expression
 }
 
+// "else if ..." を、続くif式だけを含むブロックとして読み込む
+// これにより else if の連なりは入れ子のif式として表され、評価器は通常のelseと同じく扱える
+func (p *Parser) parseElseIf() *ast.BlockStatement {
+	block := &ast.BlockStatement{Token: p.curToken}
+
+	ifExpression := p.parseIfExpression()
+	if ifExpression == nil {
+		return nil
+	}
+	block.Statements = []ast.Statement{
+		&ast.ExpressionStatement{Token: block.Token, Expression: ifExpression},
+	}
+	return block
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	// trace(fmt.Sprintf("parseBlockStatement: curToken=%s", p.curToken.Literal))
 	// defer untrace("parseBlockStatement")
@@ -307,10 +672,16 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{Token: p.curToken}
 	block.Statements = []ast.Statement{}
 
+	p.blockDepth++
+	defer func() { p.blockDepth-- }()
+
 	p.nextToken()
 
 	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
-		stmt := p.parseStatement()
+		stmt, ok := p.parseStatementOrSync()
+		if !ok {
+			continue
+		}
 		if stmt != nil {
 			block.Statements = append(block.Statements, stmt)
 		}
@@ -334,7 +705,30 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 		return nil
 	}
 
+	// 関数の本体から外側のループをbreak, continueすることはできない
+	loopDepth := p.loopDepth
+	p.loopDepth = 0
 	lit.Body = p.parseBlockStatement()
+	p.loopDepth = loopDepth
+	return lit
+}
+
+func (p *Parser) parseMacroLiteral() ast.Expression {
+	lit := &ast.MacroLiteral{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	lit.Parameters = p.parseFunctionParameters()
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	loopDepth := p.loopDepth
+	p.loopDepth = 0
+	lit.Body = p.parseBlockStatement()
+	p.loopDepth = loopDepth
 	return lit
 }
 
@@ -342,6 +736,7 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	identifiers := []*ast.Identifier{}
 
 	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
 		return identifiers
 	}
 
@@ -365,31 +760,104 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p.curToken, Function: function}
-	exp.Arguments = p.parseCallArguments()
+	exp.Arguments = p.parseExpressionList(token.RPAREN)
+	if exp.Arguments == nil {
+		return nil
+	}
 	return exp
 }
 
-func (p *Parser) parseCallArguments() []ast.Expression {
-	args := []ast.Expression{}
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	array := &ast.ArrayLiteral{Token: p.curToken}
+	array.Elements = p.parseExpressionList(token.RBRACKET)
+	if array.Elements == nil {
+		return nil
+	}
+	return array
+}
+
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
+
+	p.nextToken()
+	exp.Index = p.parseExpression(LOWEST)
+	if exp.Index == nil {
+		return nil // 添字のエラーは報告済み
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+	return exp
+}
+
+// ブロック文はif式や関数リテラルの中でのみparseBlockStatementから読み込まれるため、
+// 式の先頭に現れる'{'は常にハッシュリテラルとして扱う
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.curToken}
+	hash.Pairs = []*ast.HashPair{}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+		if key == nil {
+			return nil // キーのエラーは報告済み
+		}
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
 
-	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return args
+		value := p.parseExpression(LOWEST)
+		if value == nil {
+			return nil // 値のエラーは報告済み
+		}
+
+		hash.Pairs = append(hash.Pairs, &ast.HashPair{Key: key, Value: value})
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	return hash
+}
+
+// カンマ区切りの式をendトークンまで読み込む (関数の引数, 配列の要素)
+func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+	list := []ast.Expression{}
+
+	if p.peekTokenIs(end) {
+		p.nextToken()
+		return list
 	}
 
 	p.nextToken()
-	args = append(args, p.parseExpression(LOWEST))
+	exp := p.parseExpression(LOWEST)
+	if exp == nil {
+		// 要素のエラーは報告済み。閉じ括弧が無いというエラーを重ねて報告しない
+		return nil
+	}
+	list = append(list, exp)
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
+		exp := p.parseExpression(LOWEST)
+		if exp == nil {
+			return nil
+		}
+		list = append(list, exp)
 	}
 
-	if !p.expectPeek(token.RPAREN) {
+	if !p.expectPeek(end) {
 		return nil
 	}
-	return args
+	return list
 }
 
 func (p *Parser) peekPrecedence() int {
@@ -432,11 +900,31 @@ func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
 	p.infixParseFns[tokenType] = fn
 }
 
+// エラーメッセージの一覧を返す (位置情報が必要な場合はParseErrorsを使う)
 func (p *Parser) Errors() []string {
+	return p.errors.Messages()
+}
+
+// 位置情報付きのエラーの一覧を返す
+func (p *Parser) ParseErrors() ErrorList {
 	return p.errors
 }
 
+func (p *Parser) addError(pos token.Position, expected []token.TokenType, actual token.Token, msg string) {
+	p.errors = append(p.errors, &ParseError{
+		Pos:      pos,
+		Expected: expected,
+		Actual:   actual,
+		Msg:      msg,
+	})
+}
+
+// 字句解析器から通知されたエラーを記録する
+func (p *Parser) lexerError(pos token.Position, msg string) {
+	p.pendingErrors = append(p.pendingErrors, &ParseError{Pos: pos, Msg: msg})
+}
+
 func (p *Parser) peekError(t token.TokenType) {
 	msg := fmt.Sprintf("expected next token to be %s, got %s instead", t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.addError(p.peekToken.Pos, []token.TokenType{t}, p.peekToken, msg)
 }
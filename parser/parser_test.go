@@ -30,7 +30,10 @@ func TestLetStatements(t *testing.T) {
 						Token: token.Token{Type: token.IDENT, Literal: "x"},
 						Value: "x",
 					},
-					Value: nil,
+					Value: &ast.IntegerLiteral{
+						Token: token.Token{Type: token.INT, Literal: "5"},
+						Value: 5,
+					},
 				},
 			},
 		},
@@ -40,7 +43,7 @@ func TestLetStatements(t *testing.T) {
 				let 1 = x;
 			`,
 			expectedStatements: nil,
-			expectedErrors:     []string{"expected next token to be IDENT, got INT instead", "no prefix parse function for = found"},
+			expectedErrors:     []string{"expected next token to be IDENT, got INT instead"},
 		},
 	}
 
@@ -51,7 +54,7 @@ func TestLetStatements(t *testing.T) {
 
 			program := p.ParseProgram()
 			if len(p.Errors()) == 0 {
-				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements)
+				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements, testingHelper.IgnorePosition)
 			} else {
 				testingHelper.AssertEqual(t, tt.expectedErrors, p.Errors())
 			}
@@ -73,8 +76,11 @@ func TestReturnStatements(t *testing.T) {
 			`,
 			expectedStatements: []ast.Statement{
 				&ast.ReturnStatement{
-					Token:       token.Token{Type: token.RETURN, Literal: "return"},
-					ReturnValue: nil,
+					Token: token.Token{Type: token.RETURN, Literal: "return"},
+					ReturnValue: &ast.IntegerLiteral{
+						Token: token.Token{Type: token.INT, Literal: "10"},
+						Value: 10,
+					},
 				},
 			},
 		},
@@ -87,7 +93,7 @@ func TestReturnStatements(t *testing.T) {
 
 			program := p.ParseProgram()
 			if len(p.Errors()) == 0 {
-				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements)
+				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements, testingHelper.IgnorePosition)
 			} else {
 				testingHelper.AssertEqual(t, tt.expectedErrors, p.Errors())
 			}
@@ -126,7 +132,7 @@ func TestIdentifierExpression(t *testing.T) {
 
 			program := p.ParseProgram()
 			if len(p.Errors()) == 0 {
-				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements)
+				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements, testingHelper.IgnorePosition)
 			} else {
 				testingHelper.AssertEqual(t, tt.expectedErrors, p.Errors())
 			}
@@ -218,7 +224,7 @@ func TestParsingPrefixExpression(t *testing.T) {
 
 			program := p.ParseProgram()
 			if len(p.Errors()) == 0 {
-				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements)
+				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements, testingHelper.IgnorePosition)
 			} else {
 				testingHelper.AssertEqual(t, tt.expectedErrors, p.Errors())
 			}
@@ -443,7 +449,7 @@ func TestParsingInfixExpression(t *testing.T) {
 
 			program := p.ParseProgram()
 			if len(p.Errors()) == 0 {
-				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements)
+				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements, testingHelper.IgnorePosition)
 				testingHelper.AssertEqual(t, tt.expectedString, fmt.Sprintf("%s", program.Statements[0]))
 			} else {
 				testingHelper.AssertEqual(t, tt.expectedErrors, p.Errors())
@@ -525,7 +531,7 @@ func TestParsingBoolean(t *testing.T) {
 
 			program := p.ParseProgram()
 			if len(p.Errors()) == 0 {
-				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements)
+				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements, testingHelper.IgnorePosition)
 			} else {
 				testingHelper.AssertEqual(t, tt.expectedErrors, p.Errors())
 			}
@@ -580,7 +586,7 @@ func TestParseGroupedExpression(t *testing.T) {
 
 			program := p.ParseProgram()
 			if len(p.Errors()) == 0 {
-				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements)
+				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements, testingHelper.IgnorePosition)
 			} else {
 				testingHelper.AssertEqual(t, tt.expectedErrors, p.Errors())
 			}
@@ -693,7 +699,7 @@ func TestIfExpression(t *testing.T) {
 
 			program := p.ParseProgram()
 			if len(p.Errors()) == 0 {
-				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements)
+				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements, testingHelper.IgnorePosition)
 			} else {
 				testingHelper.AssertEqual(t, tt.expectedErrors, p.Errors())
 			}
@@ -701,6 +707,105 @@ func TestIfExpression(t *testing.T) {
 	}
 }
 
+func TestParseElseIf(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedString string
+		expectedErrors []string
+	}{
+		{
+			name:           "success: else if",
+			input:          `if (a) { x } else if (b) { y }`,
+			expectedString: "if a { x } else if b { y }",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "success: else ifとelseの連なり",
+			input:          `if (a) { x } else if (b) { y } else if (c) { z } else { w }`,
+			expectedString: "if a { x } else if b { y } else if c { z } else { w }",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "success: elseのブロック内のif式はelse ifと区別する",
+			input:          `if (a) { x } else { if (b) { y } }`,
+			expectedString: "if a { x } else { if b { y } }",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "success: 条件が中置式で空の分岐",
+			input:          `if (a < b) {} else {}`,
+			expectedString: "if (a < b) {} else {}",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "failure: else ifの条件の括弧が無い",
+			input:          `if (a) { x } else if b { y }`,
+			expectedString: "",
+			expectedErrors: []string{"expected next token to be (, got IDENT instead"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+
+			program := p.ParseProgram()
+			testingHelper.AssertEqual(t, tt.expectedErrors, p.Errors())
+			testingHelper.AssertEqual(t, tt.expectedString, program.String())
+		})
+	}
+}
+
+// else ifは、続くif式だけを含むブロックとして入れ子になる
+func TestParseElseIfStructure(t *testing.T) {
+	input := `if (a) { x } else if (b) { y }`
+	ident := func(name string) *ast.Identifier {
+		return &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+	}
+	block := func(name string) *ast.BlockStatement {
+		return &ast.BlockStatement{
+			Token: token.Token{Type: token.LBRACE, Literal: "{"},
+			Statements: []ast.Statement{
+				&ast.ExpressionStatement{Token: token.Token{Type: token.IDENT, Literal: name}, Expression: ident(name)},
+			},
+		}
+	}
+	ifToken := token.Token{Type: token.IF, Literal: "if"}
+
+	expectedStatements := []ast.Statement{
+		&ast.ExpressionStatement{
+			Token: ifToken,
+			Expression: &ast.IfExpression{
+				Token:       ifToken,
+				Condition:   ident("a"),
+				Consequence: block("x"),
+				Alternative: &ast.BlockStatement{
+					Token: ifToken,
+					Statements: []ast.Statement{
+						&ast.ExpressionStatement{
+							Token: ifToken,
+							Expression: &ast.IfExpression{
+								Token:       ifToken,
+								Condition:   ident("b"),
+								Consequence: block("y"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	testingHelper.AssertEqual(t, []string{}, p.Errors())
+	testingHelper.AssertEqual(t, expectedStatements, program.Statements, testingHelper.IgnorePosition)
+}
+
 func TestParseFunctionLiteral(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -833,6 +938,7 @@ func TestParseFunctionLiteral(t *testing.T) {
 								},
 							},
 						},
+						Name: "f",
 					},
 				},
 				&ast.LetStatement{
@@ -857,6 +963,244 @@ func TestParseFunctionLiteral(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "success: 引数なしの関数",
+			input: `
+				fn () { 1 }
+			`,
+			expectedStatements: []ast.Statement{
+				&ast.ExpressionStatement{
+					Token: token.Token{Type: token.FUNCTION, Literal: "fn"},
+					Expression: &ast.FunctionLiteral{
+						Token:      token.Token{Type: token.FUNCTION, Literal: "fn"},
+						Parameters: []*ast.Identifier{},
+						Body: &ast.BlockStatement{
+							Token: token.Token{Type: token.LBRACE, Literal: "{"},
+							Statements: []ast.Statement{
+								&ast.ExpressionStatement{
+									Token: token.Token{Type: token.INT, Literal: "1"},
+									Expression: &ast.IntegerLiteral{
+										Token: token.Token{Type: token.INT, Literal: "1"},
+										Value: 1,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+
+			program := p.ParseProgram()
+			if len(p.Errors()) == 0 {
+				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements, testingHelper.IgnorePosition)
+			} else {
+				testingHelper.AssertEqual(t, tt.expectedErrors, p.Errors())
+			}
+		})
+	}
+}
+
+func TestParseStringLiteral(t *testing.T) {
+	tests := []struct {
+		name               string
+		input              string
+		expectedStatements []ast.Statement
+		expectedString     string
+		expectedErrors     []string
+	}{
+		{
+			name: "success: 文字列",
+			input: `
+				"hello\tworld";
+			`,
+			expectedStatements: []ast.Statement{
+				&ast.ExpressionStatement{
+					Token: token.Token{Type: token.STRING, Literal: "hello\tworld"},
+					Expression: &ast.StringLiteral{
+						Token: token.Token{Type: token.STRING, Literal: "hello\tworld"},
+						Value: "hello\tworld",
+					},
+				},
+			},
+			expectedString: `"hello\tworld"`,
+		},
+		{
+			name: "success: 文字列の連結",
+			input: `
+				"a" + "b";
+			`,
+			expectedStatements: []ast.Statement{
+				&ast.ExpressionStatement{
+					Token: token.Token{Type: token.STRING, Literal: "a"},
+					Expression: &ast.InfixExpression{
+						Token: token.Token{Type: token.PLUS, Literal: "+"},
+						Left: &ast.StringLiteral{
+							Token: token.Token{Type: token.STRING, Literal: "a"},
+							Value: "a",
+						},
+						Operator: "+",
+						Right: &ast.StringLiteral{
+							Token: token.Token{Type: token.STRING, Literal: "b"},
+							Value: "b",
+						},
+					},
+				},
+			},
+			expectedString: `("a" + "b")`,
+		},
+		{
+			name: "failure: 閉じられていない文字列",
+			input: `
+				let s = "hello;
+			`,
+			expectedErrors: []string{"unterminated string literal"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+
+			program := p.ParseProgram()
+			if len(p.Errors()) == 0 {
+				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements, testingHelper.IgnorePosition)
+				testingHelper.AssertEqual(t, tt.expectedString, program.Statements[0].String())
+			} else {
+				testingHelper.AssertEqual(t, tt.expectedErrors, p.Errors())
+			}
+		})
+	}
+}
+
+func TestParseArrayLiteral(t *testing.T) {
+	tests := []struct {
+		name               string
+		input              string
+		expectedStatements []ast.Statement
+		expectedString     string
+		expectedErrors     []string
+	}{
+		{
+			name: "success: 配列",
+			input: `
+				[1, 2 * 2];
+			`,
+			expectedStatements: []ast.Statement{
+				&ast.ExpressionStatement{
+					Token: token.Token{Type: token.LBRACKET, Literal: "["},
+					Expression: &ast.ArrayLiteral{
+						Token: token.Token{Type: token.LBRACKET, Literal: "["},
+						Elements: []ast.Expression{
+							&ast.IntegerLiteral{
+								Token: token.Token{Type: token.INT, Literal: "1"},
+								Value: 1,
+							},
+							&ast.InfixExpression{
+								Token: token.Token{Type: token.ASTERISK, Literal: "*"},
+								Left: &ast.IntegerLiteral{
+									Token: token.Token{Type: token.INT, Literal: "2"},
+									Value: 2,
+								},
+								Operator: "*",
+								Right: &ast.IntegerLiteral{
+									Token: token.Token{Type: token.INT, Literal: "2"},
+									Value: 2,
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedString: "[1, (2 * 2)]",
+		},
+		{
+			name: "success: 空の配列",
+			input: `
+				[];
+			`,
+			expectedStatements: []ast.Statement{
+				&ast.ExpressionStatement{
+					Token: token.Token{Type: token.LBRACKET, Literal: "["},
+					Expression: &ast.ArrayLiteral{
+						Token:    token.Token{Type: token.LBRACKET, Literal: "["},
+						Elements: []ast.Expression{},
+					},
+				},
+			},
+			expectedString: "[]",
+		},
+		{
+			name: "failure: 閉じられていない配列",
+			input: `
+				[1, 2;
+			`,
+			expectedErrors: []string{"expected next token to be ], got ; instead"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+
+			program := p.ParseProgram()
+			if len(p.Errors()) == 0 {
+				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements, testingHelper.IgnorePosition)
+				testingHelper.AssertEqual(t, tt.expectedString, program.Statements[0].String())
+			} else {
+				testingHelper.AssertEqual(t, tt.expectedErrors, p.Errors())
+			}
+		})
+	}
+}
+
+func TestParseIndexExpression(t *testing.T) {
+	tests := []struct {
+		name               string
+		input              string
+		expectedStatements []ast.Statement
+		expectedString     string
+		expectedErrors     []string
+	}{
+		{
+			name: "success: 添字アクセス",
+			input: `
+				arr[1 + 1];
+			`,
+			expectedStatements: []ast.Statement{
+				&ast.ExpressionStatement{
+					Token: token.Token{Type: token.IDENT, Literal: "arr"},
+					Expression: &ast.IndexExpression{
+						Token: token.Token{Type: token.LBRACKET, Literal: "["},
+						Left: &ast.Identifier{
+							Token: token.Token{Type: token.IDENT, Literal: "arr"},
+							Value: "arr",
+						},
+						Index: &ast.InfixExpression{
+							Token: token.Token{Type: token.PLUS, Literal: "+"},
+							Left: &ast.IntegerLiteral{
+								Token: token.Token{Type: token.INT, Literal: "1"},
+								Value: 1,
+							},
+							Operator: "+",
+							Right: &ast.IntegerLiteral{
+								Token: token.Token{Type: token.INT, Literal: "1"},
+								Value: 1,
+							},
+						},
+					},
+				},
+			},
+			expectedString: "(arr[(1 + 1)])",
+		},
 	}
 
 	for _, tt := range tests {
@@ -866,10 +1210,605 @@ func TestParseFunctionLiteral(t *testing.T) {
 
 			program := p.ParseProgram()
 			if len(p.Errors()) == 0 {
-				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements)
+				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements, testingHelper.IgnorePosition)
+				testingHelper.AssertEqual(t, tt.expectedString, program.Statements[0].String())
 			} else {
 				testingHelper.AssertEqual(t, tt.expectedErrors, p.Errors())
 			}
 		})
 	}
 }
+
+func TestOperatorPrecedenceParsing(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedString string
+	}{
+		{
+			name:           "success: 添字アクセスは乗算より優先",
+			input:          `a * [1, 2, 3, 4][b * c] * d`,
+			expectedString: "((a * ([1, 2, 3, 4][(b * c)])) * d)",
+		},
+		{
+			name:           "success: 関数の引数内の添字アクセス",
+			input:          `add(a * b[2], b[1], 2 * [1, 2][1])`,
+			expectedString: "add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
+		},
+		{
+			name:           "success: 関数の戻り値への添字アクセス",
+			input:          `f(x)[0]`,
+			expectedString: "(f(x)[0])",
+		},
+		{
+			name:           "success: &&は||より優先",
+			input:          `a || b && c || d`,
+			expectedString: "((a || (b && c)) || d)",
+		},
+		{
+			name:           "success: 比較は論理演算より優先",
+			input:          `a <= b && c >= d == true`,
+			expectedString: "((a <= b) && ((c >= d) == true))",
+		},
+		{
+			name:           "success: 剰余は乗算と同じ優先順位",
+			input:          `a + b % c * d`,
+			expectedString: "(a + ((b % c) * d))",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+
+			program := p.ParseProgram()
+			testingHelper.AssertEqual(t, []string{}, p.Errors())
+			testingHelper.AssertEqual(t, tt.expectedString, program.String())
+		})
+	}
+}
+
+func TestParseHashLiteral(t *testing.T) {
+	tests := []struct {
+		name               string
+		input              string
+		expectedStatements []ast.Statement
+		expectedString     string
+		expectedErrors     []string
+	}{
+		{
+			name: "success: ハッシュ",
+			input: `
+				{"one": 1, 2: true};
+			`,
+			expectedStatements: []ast.Statement{
+				&ast.ExpressionStatement{
+					Token: token.Token{Type: token.LBRACE, Literal: "{"},
+					Expression: &ast.HashLiteral{
+						Token: token.Token{Type: token.LBRACE, Literal: "{"},
+						Pairs: []*ast.HashPair{
+							{
+								Key: &ast.StringLiteral{
+									Token: token.Token{Type: token.STRING, Literal: "one"},
+									Value: "one",
+								},
+								Value: &ast.IntegerLiteral{
+									Token: token.Token{Type: token.INT, Literal: "1"},
+									Value: 1,
+								},
+							},
+							{
+								Key: &ast.IntegerLiteral{
+									Token: token.Token{Type: token.INT, Literal: "2"},
+									Value: 2,
+								},
+								Value: &ast.Boolean{
+									Token: token.Token{Type: token.TRUE, Literal: "true"},
+									Value: true,
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedString: `{"one": 1, 2: true}`,
+		},
+		{
+			name: "success: 空のハッシュ",
+			input: `
+				let h = {};
+			`,
+			expectedStatements: []ast.Statement{
+				&ast.LetStatement{
+					Token: token.Token{Type: token.LET, Literal: "let"},
+					Name: &ast.Identifier{
+						Token: token.Token{Type: token.IDENT, Literal: "h"},
+						Value: "h",
+					},
+					Value: &ast.HashLiteral{
+						Token: token.Token{Type: token.LBRACE, Literal: "{"},
+						Pairs: []*ast.HashPair{},
+					},
+				},
+			},
+			expectedString: "let h = {};",
+		},
+		{
+			name: "success: 値が式のハッシュ",
+			input: `
+				{"sum": 0 + 1}
+			`,
+			expectedStatements: []ast.Statement{
+				&ast.ExpressionStatement{
+					Token: token.Token{Type: token.LBRACE, Literal: "{"},
+					Expression: &ast.HashLiteral{
+						Token: token.Token{Type: token.LBRACE, Literal: "{"},
+						Pairs: []*ast.HashPair{
+							{
+								Key: &ast.StringLiteral{
+									Token: token.Token{Type: token.STRING, Literal: "sum"},
+									Value: "sum",
+								},
+								Value: &ast.InfixExpression{
+									Token: token.Token{Type: token.PLUS, Literal: "+"},
+									Left: &ast.IntegerLiteral{
+										Token: token.Token{Type: token.INT, Literal: "0"},
+										Value: 0,
+									},
+									Operator: "+",
+									Right: &ast.IntegerLiteral{
+										Token: token.Token{Type: token.INT, Literal: "1"},
+										Value: 1,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedString: `{"sum": (0 + 1)}`,
+		},
+		{
+			name: "failure: コロンが無いハッシュ",
+			input: `
+				{"one" 1}
+			`,
+			expectedErrors: []string{"expected next token to be :, got INT instead"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+
+			program := p.ParseProgram()
+			if len(p.Errors()) == 0 {
+				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements, testingHelper.IgnorePosition)
+				testingHelper.AssertEqual(t, tt.expectedString, program.Statements[0].String())
+			} else {
+				testingHelper.AssertEqual(t, tt.expectedErrors, p.Errors())
+			}
+		})
+	}
+}
+
+func TestParseFloatLiteral(t *testing.T) {
+	tests := []struct {
+		name               string
+		input              string
+		expectedStatements []ast.Statement
+		expectedErrors     []string
+	}{
+		{
+			name: "success: 浮動小数点数",
+			input: `
+				1.5e3 * 2;
+			`,
+			expectedStatements: []ast.Statement{
+				&ast.ExpressionStatement{
+					Token: token.Token{Type: token.FLOAT, Literal: "1.5e3"},
+					Expression: &ast.InfixExpression{
+						Token: token.Token{Type: token.ASTERISK, Literal: "*"},
+						Left: &ast.FloatLiteral{
+							Token: token.Token{Type: token.FLOAT, Literal: "1.5e3"},
+							Value: 1500,
+						},
+						Operator: "*",
+						Right: &ast.IntegerLiteral{
+							Token: token.Token{Type: token.INT, Literal: "2"},
+							Value: 2,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "failure: 範囲外の浮動小数点数",
+			input: `
+				1e400;
+			`,
+			expectedErrors: []string{`could not parse "1e400" as float`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+
+			program := p.ParseProgram()
+			if len(p.Errors()) == 0 {
+				testingHelper.AssertEqual(t, tt.expectedStatements, program.Statements, testingHelper.IgnorePosition)
+			} else {
+				testingHelper.AssertEqual(t, tt.expectedErrors, p.Errors())
+			}
+		})
+	}
+}
+
+func TestParseIntegerLiteral(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedValue  int64
+		expectedErrors []string
+	}{
+		{
+			name:          "success: 16進数",
+			input:         `0xff`,
+			expectedValue: 255,
+		},
+		{
+			name:          "success: 8進数",
+			input:         `0o17`,
+			expectedValue: 15,
+		},
+		{
+			name:          "success: 2進数",
+			input:         `0b1010`,
+			expectedValue: 10,
+		},
+		{
+			name:          "success: 0",
+			input:         `0`,
+			expectedValue: 0,
+		},
+		{
+			name:          "success: 区切り文字",
+			input:         `1_000_000`,
+			expectedValue: 1000000,
+		},
+		{
+			name:          "success: int64の最大値",
+			input:         `0x7fff_ffff_ffff_ffff`,
+			expectedValue: 9223372036854775807,
+		},
+		{
+			name:           "failure: int64の範囲外",
+			input:          `9223372036854775808`,
+			expectedErrors: []string{"1:1: integer literal 9223372036854775808 out of range for int64"},
+		},
+		{
+			name:           "failure: 数字の無い16進数は1つのエラーになる",
+			input:          `let x = 0x;`,
+			expectedErrors: []string{"1:9: hexadecimal literal has no digits"},
+		},
+		{
+			name:           "failure: 連続した区切り文字は1つのエラーになる",
+			input:          `1__0`,
+			expectedErrors: []string{"1:2: '_' must separate successive digits"},
+		},
+		{
+			name:           "failure: 先頭が0の10進整数は8進数として解釈しない",
+			input:          `017`,
+			expectedErrors: []string{"1:1: leading zeros in decimal integer literals are not permitted; use an 0o prefix for octal integers"},
+		},
+		{
+			name:           "failure: 先頭が0で8進数として不正な10進整数",
+			input:          `08`,
+			expectedErrors: []string{"1:1: leading zeros in decimal integer literals are not permitted; use an 0o prefix for octal integers"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+
+			program := p.ParseProgram()
+			if len(p.Errors()) == 0 {
+				stmt := program.Statements[0].(*ast.ExpressionStatement)
+				testingHelper.AssertEqual(t, tt.expectedValue, stmt.Expression.(*ast.IntegerLiteral).Value)
+			} else {
+				var errors []string
+				for _, e := range p.ParseErrors() {
+					errors = append(errors, e.Error())
+				}
+				testingHelper.AssertEqual(t, tt.expectedErrors, errors)
+			}
+		})
+	}
+}
+
+func TestParseWhileStatement(t *testing.T) {
+	input := `while (x < 3) { break; }`
+	expectedStatements := []ast.Statement{
+		&ast.WhileStatement{
+			Token: token.Token{Type: token.WHILE, Literal: "while"},
+			Condition: &ast.InfixExpression{
+				Token: token.Token{Type: token.LT, Literal: "<"},
+				Left: &ast.Identifier{
+					Token: token.Token{Type: token.IDENT, Literal: "x"},
+					Value: "x",
+				},
+				Operator: "<",
+				Right: &ast.IntegerLiteral{
+					Token: token.Token{Type: token.INT, Literal: "3"},
+					Value: 3,
+				},
+			},
+			Body: &ast.BlockStatement{
+				Token: token.Token{Type: token.LBRACE, Literal: "{"},
+				Statements: []ast.Statement{
+					&ast.BreakStatement{
+						Token: token.Token{Type: token.BREAK, Literal: "break"},
+					},
+				},
+			},
+		},
+	}
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	testingHelper.AssertEqual(t, []string{}, p.Errors())
+	testingHelper.AssertEqual(t, expectedStatements, program.Statements, testingHelper.IgnorePosition)
+}
+
+func TestParseLoopStatements(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedString string
+		expectedErrors []string
+	}{
+		{
+			name:           "success: for文",
+			input:          `for (let i = 0; i < 10; let i = i + 1) { continue; }`,
+			expectedString: "for (let i = 0; (i < 10); let i = (i + 1)) continue;",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "success: 初期化, 条件, 後処理を省略したfor文",
+			input:          `for (;;) { break }`,
+			expectedString: "for (; ; ) break;",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "success: 式文を初期化と後処理に使うfor文",
+			input:          `for (f(); x; g(x)) { x }`,
+			expectedString: "for (f(); x; g(x)) x",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "success: for-in文",
+			input:          `for (x in [1, 2]) { puts(x); }`,
+			expectedString: "for (x in [1, 2]) puts(x)",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "success: ループの中のif式でbreakできる",
+			input:          `while (true) { if (x) { break; } }`,
+			expectedString: "while true if x { break; }",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "failure: ループの外のbreak",
+			input:          `break;`,
+			expectedString: "",
+			expectedErrors: []string{"break statement outside loop"},
+		},
+		{
+			name:           "failure: ループの中の関数からはcontinueできない",
+			input:          `while (true) { let f = fn() { continue; }; }`,
+			expectedString: "",
+			expectedErrors: []string{"continue statement outside loop"},
+		},
+		{
+			name:           "failure: for文の区切りの';'が無い",
+			input:          `for (let i = 0 i < 10) { i }`,
+			expectedString: "",
+			expectedErrors: []string{"expected next token to be ;, got IDENT instead"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+
+			program := p.ParseProgram()
+			testingHelper.AssertEqual(t, tt.expectedErrors, p.Errors())
+			testingHelper.AssertEqual(t, tt.expectedString, program.String())
+		})
+	}
+}
+
+func TestParseAssignExpression(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedString string
+		expectedErrors []string
+	}{
+		{
+			name:           "success: 代入",
+			input:          `x = 1 + 2`,
+			expectedString: "(x = (1 + 2))",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "success: 代入は右結合",
+			input:          `a = b = c || d`,
+			expectedString: "(a = (b = (c || d)))",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "success: 複合代入",
+			input:          `x += 1; x -= 2; x *= 3; x /= 4;`,
+			expectedString: "(x += 1)(x -= 2)(x *= 3)(x /= 4)",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "success: 添字アクセスへの代入",
+			input:          `arr[i + 1] = h["k"] += 1`,
+			expectedString: "((arr[(i + 1)]) = ((h[\"k\"]) += 1))",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "success: for文の後処理での代入",
+			input:          `for (let i = 0; i < 3; i += 1) { i }`,
+			expectedString: "for (let i = 0; (i < 3); (i += 1)) i",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "failure: 代入できない左辺",
+			input:          `f(x) = 1`,
+			expectedString: "",
+			expectedErrors: []string{"cannot assign to f(x)"},
+		},
+		{
+			name:           "failure: 演算の結果には代入できない",
+			input:          `a + b = 1`,
+			expectedString: "",
+			expectedErrors: []string{"cannot assign to (a + b)"},
+		},
+		{
+			name:           "failure: 左辺の途中にエラーがある",
+			input:          `a + ) = 1`,
+			expectedString: "",
+			expectedErrors: []string{"no prefix parse function for ) found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+
+			program := p.ParseProgram()
+			testingHelper.AssertEqual(t, tt.expectedErrors, p.Errors())
+			testingHelper.AssertEqual(t, tt.expectedString, program.String())
+		})
+	}
+}
+
+func TestParseConditionalExpression(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedString string
+		expectedErrors []string
+	}{
+		{
+			name:           "success: 条件演算子",
+			input:          `x > 0 ? x : -x`,
+			expectedString: "((x > 0) ? x : (-x))",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "success: 条件演算子は右結合",
+			input:          `a ? b : c ? d : e`,
+			expectedString: "(a ? b : (c ? d : e))",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "success: 条件演算子は||より優先順位が低い",
+			input:          `a || b ? c && d : e || f`,
+			expectedString: "((a || b) ? (c && d) : (e || f))",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "success: 条件演算子は代入より優先順位が高い",
+			input:          `x = a ? b : c`,
+			expectedString: "(x = (a ? b : c))",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "success: null合体演算子は左結合",
+			input:          `a ?? b ?? c`,
+			expectedString: "((a ?? b) ?? c)",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "success: null合体演算子は||より低く条件演算子より高い",
+			input:          `a || b ?? c ? d : e`,
+			expectedString: "(((a || b) ?? c) ? d : e)",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "success: ハッシュの値に条件演算子を書ける",
+			input:          `{"k": a ? 1 : 2}`,
+			expectedString: "{\"k\": (a ? 1 : 2)}",
+			expectedErrors: []string{},
+		},
+		{
+			name:           "failure: ':'が無い",
+			input:          `a ? b`,
+			expectedString: "",
+			expectedErrors: []string{"expected next token to be :, got EOF instead"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+
+			program := p.ParseProgram()
+			testingHelper.AssertEqual(t, tt.expectedErrors, p.Errors())
+			testingHelper.AssertEqual(t, tt.expectedString, program.String())
+		})
+	}
+}
+
+func TestParseMacroLiteral(t *testing.T) {
+	input := `macro(x, y) { x + y; }`
+	ident := func(name string) *ast.Identifier {
+		return &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+	}
+	expectedStatements := []ast.Statement{
+		&ast.ExpressionStatement{
+			Token: token.Token{Type: token.MACRO, Literal: "macro"},
+			Expression: &ast.MacroLiteral{
+				Token:      token.Token{Type: token.MACRO, Literal: "macro"},
+				Parameters: []*ast.Identifier{ident("x"), ident("y")},
+				Body: &ast.BlockStatement{
+					Token: token.Token{Type: token.LBRACE, Literal: "{"},
+					Statements: []ast.Statement{
+						&ast.ExpressionStatement{
+							Token: token.Token{Type: token.IDENT, Literal: "x"},
+							Expression: &ast.InfixExpression{
+								Token:    token.Token{Type: token.PLUS, Literal: "+"},
+								Left:     ident("x"),
+								Operator: "+",
+								Right:    ident("y"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	testingHelper.AssertEqual(t, []string{}, p.Errors())
+	testingHelper.AssertEqual(t, expectedStatements, program.Statements, testingHelper.IgnorePosition)
+	testingHelper.AssertEqual(t, "macro(x, y) (x + y)", program.String())
+}
@@ -0,0 +1,714 @@
+// Monkeyのプログラムを、決まった書式のソースコードとして出力する
+package printer
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mahiro72/monkey-lang/ast"
+	"github.com/mahiro72/monkey-lang/lexer"
+	"github.com/mahiro72/monkey-lang/parser"
+	"github.com/mahiro72/monkey-lang/token"
+)
+
+// ソースコードを構文解析し、整形したソースコードを返す
+// 文の間のコメントは文の前か同じ行の文の末尾に、式の途中のコメントは元のソースで直前にあったトークンの後に残す
+// 構文エラーがある場合はparser.ErrorListを返す
+func Format(filename string, src []byte) ([]byte, error) {
+	p := parser.New(lexer.NewWithFilename(filename, string(src)))
+	program := p.ParseProgram()
+	if err := p.ParseErrors().Err(); err != nil {
+		return nil, err
+	}
+
+	pr := newPrinter(string(src))
+	pr.program(program)
+	return pr.out.Bytes(), nil
+}
+
+// プログラムを整形してwに書き込む。ASTだけからはコメントは復元できないので出力されない
+func Fprint(w io.Writer, program *ast.Program) error {
+	pr := &printer{}
+	pr.program(program)
+	_, err := w.Write(pr.out.Bytes())
+	return err
+}
+
+type printer struct {
+	out    bytes.Buffer
+	indent int
+
+	// 式の途中に出力したコメントの後の区切り。次に書き出す文字列の前に空白や改行を補う
+	needSpace      bool // ブロックコメントの後
+	pendingNewline bool // 行コメントの後
+
+	// 以下はソースコードがある場合のみ設定し、コメントと空行を復元するのに使う
+	src           string
+	tokens        []token.Token // コメントを含む全てのトークン (EOFを除く)
+	printed       map[int]bool  // 出力済みのコメントのトークンの添字
+	closings      map[int]int   // '(', '[', '{'のオフセットから対応する閉じ括弧のオフセット
+	ownLine       map[int]bool  // コメントのトークンの添字から、そのコメントが行の先頭にあるかどうか
+	lineCommented map[int]bool  // "//"のコメントを直接含む開き括弧のオフセット
+	next          int           // flushで次に調べるトークンの添字
+}
+
+func newPrinter(src string) *printer {
+	p := &printer{
+		src:           src,
+		printed:       map[int]bool{},
+		closings:      map[int]int{},
+		ownLine:       map[int]bool{},
+		lineCommented: map[int]bool{},
+	}
+
+	l := lexer.New(src)
+	l.SetEmitComments(true)
+	var opens []int
+	prevLine := 0 // 直前のコメント以外のトークンの終わりの行
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LPAREN, token.LBRACKET, token.LBRACE:
+			opens = append(opens, tok.Pos.Offset)
+		case token.RPAREN, token.RBRACKET, token.RBRACE:
+			if len(opens) > 0 {
+				p.closings[opens[len(opens)-1]] = tok.Pos.Offset
+				opens = opens[:len(opens)-1]
+			}
+		}
+
+		if tok.Type == token.COMMENT {
+			p.ownLine[len(p.tokens)] = tok.Pos.Line > prevLine
+			if len(opens) > 0 && strings.HasPrefix(tok.Literal, "//") {
+				p.lineCommented[opens[len(opens)-1]] = true
+			}
+		} else {
+			prevLine = tok.End.Line
+		}
+		p.tokens = append(p.tokens, tok)
+	}
+	return p
+}
+
+func (p *printer) write(s string) {
+	if s == "" {
+		return
+	}
+	switch {
+	case p.pendingNewline && s[0] != '\n':
+		// 行コメントの後に式が続く場合は、次の行に字下げを1段深くして続ける
+		p.out.WriteString("\n" + strings.Repeat("\t", p.indent+1))
+		s = strings.TrimLeft(s, " ")
+	case p.needSpace && !strings.ContainsRune(" \n,;)]}", rune(s[0])):
+		p.out.WriteString(" ")
+	}
+	p.pendingNewline, p.needSpace = false, false
+	p.out.WriteString(s)
+}
+
+func (p *printer) writeIndent() {
+	p.write(strings.Repeat("\t", p.indent))
+}
+
+func (p *printer) program(program *ast.Program) {
+	p.statementList(program.Statements, len(p.src))
+}
+
+// 文を1行ずつ出力する。endは文の並びの終わり (ブロックの'}'やソースの終端) のオフセット
+func (p *printer) statementList(stmts []ast.Statement, end int) {
+	first := true
+	for i, stmt := range stmts {
+		var next ast.Statement
+		limit := end
+		if i+1 < len(stmts) {
+			next = stmts[i+1]
+			if pos, ok := p.position(next); ok {
+				limit = pos.Offset
+			}
+		}
+
+		pos, hasPos := p.position(stmt)
+		if hasPos {
+			p.comments(pos.Offset, &first)
+			p.blankLine(pos, first)
+		}
+
+		p.writeIndent()
+		p.statement(stmt, next)
+		if hasPos {
+			p.trailingComments(pos.Offset, limit)
+		}
+		p.write("\n")
+		first = false
+	}
+	if p.tokens != nil {
+		p.comments(end, &first)
+	}
+}
+
+// 文の開始位置。ソースコードが無い場合や、位置を持たない文の場合はfalseを返す
+func (p *printer) position(stmt ast.Statement) (token.Position, bool) {
+	if p.tokens == nil {
+		return token.Position{}, false
+	}
+	var tok token.Token
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		tok = stmt.Token
+	case *ast.ReturnStatement:
+		tok = stmt.Token
+	case *ast.ExpressionStatement:
+		tok = stmt.Token
+	case *ast.BlockStatement:
+		tok = stmt.Token
+	case *ast.WhileStatement:
+		tok = stmt.Token
+	case *ast.ForStatement:
+		tok = stmt.Token
+	case *ast.ForInStatement:
+		tok = stmt.Token
+	case *ast.BreakStatement:
+		tok = stmt.Token
+	case *ast.ContinueStatement:
+		tok = stmt.Token
+	}
+	return tok.Pos, tok.Pos.IsValid()
+}
+
+// offsetより前にあるトークンの添字を返す (無い場合は-1)
+func (p *printer) tokenBefore(offset int) int {
+	return sort.Search(len(p.tokens), func(i int) bool {
+		return p.tokens[i].Pos.Offset >= offset
+	}) - 1
+}
+
+// 元のソースで直前の行との間に空行があれば、1行だけ空行を出力する
+func (p *printer) blankLine(pos token.Position, first bool) {
+	if first {
+		return
+	}
+	if i := p.tokenBefore(pos.Offset); i >= 0 && pos.Line-p.tokens[i].End.Line >= 2 {
+		p.write("\n")
+	}
+}
+
+// beforeより前にある未出力のコメントを、それぞれ1行として出力する
+func (p *printer) comments(before int, first *bool) {
+	for i, tok := range p.tokens {
+		if tok.Pos.Offset >= before {
+			break
+		}
+		if tok.Type != token.COMMENT || p.printed[i] {
+			continue
+		}
+		p.printed[i] = true
+
+		p.blankLine(tok.Pos, *first)
+		p.writeIndent()
+		p.write(commentText(tok))
+		p.write("\n")
+		*first = false
+	}
+}
+
+// 文の最後のトークンと同じ行にあるコメントを、文の末尾に出力する
+// startからlimitまでが文の範囲で、それ以外の行のコメントは次の文の前に出力される
+func (p *printer) trailingComments(start, limit int) {
+	last := p.tokenBefore(limit)
+	for last >= 0 && p.tokens[last].Type == token.COMMENT {
+		last--
+	}
+	if last < 0 || p.tokens[last].Pos.Offset < start {
+		return
+	}
+	line := p.tokens[last].End.Line
+
+	for i := p.tokenBefore(start) + 1; i < len(p.tokens) && p.tokens[i].Pos.Offset < limit; i++ {
+		tok := p.tokens[i]
+		if tok.Type != token.COMMENT || p.printed[i] || tok.Pos.Line != line {
+			continue
+		}
+		p.printed[i] = true
+		p.write(" ")
+		p.write(commentText(tok))
+	}
+}
+
+// beforeより前にある未出力のコメントを、式の途中に続けて出力する
+// 出力はソースコードと同じ順に進むので、各コメントは元のソースで直前にあったトークンの後に置かれる
+func (p *printer) flush(before int) {
+	for ; p.next < len(p.tokens) && p.tokens[p.next].Pos.Offset < before; p.next++ {
+		if p.tokens[p.next].Type == token.COMMENT && !p.printed[p.next] {
+			p.inlineComment(p.next)
+		}
+	}
+}
+
+func (p *printer) inlineComment(i int) {
+	p.printed[i] = true
+	tok := p.tokens[i]
+
+	// 直前がコメントの場合の区切りはwriteが補う。ブロックコメントは開き括弧の直後に続けて書く
+	lineComment := strings.HasPrefix(tok.Literal, "//")
+	separators := " \t\n([{"
+	if lineComment {
+		separators = " \t\n"
+	}
+	out := p.out.Bytes()
+	if !p.pendingNewline && !p.needSpace && len(out) > 0 && !strings.ContainsRune(separators, rune(out[len(out)-1])) {
+		p.out.WriteString(" ")
+	}
+	p.write(commentText(tok))
+	if lineComment {
+		p.pendingNewline = true
+	} else {
+		p.needSpace = true
+	}
+}
+
+// beforeより前にある未出力のコメントを、それぞれ1行として出力する (複数行に分けた並びの中で使う)
+func (p *printer) ownLineComments(before int) {
+	for i := p.next; i < len(p.tokens) && p.tokens[i].Pos.Offset < before; i++ {
+		if p.tokens[i].Type != token.COMMENT || p.printed[i] {
+			continue
+		}
+		p.printed[i] = true
+		p.write("\n")
+		p.writeIndent()
+		p.write(commentText(p.tokens[i]))
+	}
+}
+
+// beforeより前にある未出力のコメントのうち、直前のトークンと同じ行にあるものを行末に出力する
+func (p *printer) lineComments(before int) {
+	for i := p.next; i < len(p.tokens) && p.tokens[i].Pos.Offset < before; i++ {
+		if p.tokens[i].Type != token.COMMENT || p.printed[i] {
+			continue
+		}
+		if p.ownLine[i] {
+			return
+		}
+		p.inlineComment(i)
+	}
+}
+
+// offsetより前にある、コメントと'('以外の最後のトークンのオフセット (ソースコードが無い場合は0)
+// 式の前の区切り (',', ':', else, ')'など) の位置を求めるのに使う。式を囲む括弧は読み飛ばす
+func (p *printer) precedingToken(offset int) int {
+	for i := p.tokenBefore(offset); i >= 0; i-- {
+		switch p.tokens[i].Type {
+		case token.COMMENT, token.LPAREN:
+			continue
+		}
+		return p.tokens[i].Pos.Offset
+	}
+	return 0
+}
+
+// offsetより後にある、コメント以外の最初のトークンのオフセット (ソースコードが無い場合は0)
+func (p *printer) followingToken(offset int) int {
+	for i := p.tokenBefore(offset+1) + 1; i < len(p.tokens); i++ {
+		if p.tokens[i].Type != token.COMMENT {
+			return p.tokens[i].Pos.Offset
+		}
+	}
+	return 0
+}
+
+// offsetにあるトークンの行番号
+func (p *printer) lineOf(offset int) int {
+	i := p.tokenBefore(offset) + 1
+	if i < len(p.tokens) {
+		return p.tokens[i].Pos.Line
+	}
+	return 0
+}
+
+// 式の最も左にあるトークンのオフセット (式を囲む括弧は含まない)
+func startOffset(exp ast.Expression) int {
+	switch exp := exp.(type) {
+	case *ast.InfixExpression:
+		return startOffset(exp.Left)
+	case *ast.AssignExpression:
+		return startOffset(exp.Target)
+	case *ast.ConditionalExpression:
+		return startOffset(exp.Condition)
+	case *ast.CallExpression:
+		return startOffset(exp.Function)
+	case *ast.IndexExpression:
+		return startOffset(exp.Left)
+	case *ast.Identifier:
+		return exp.Token.Pos.Offset
+	case *ast.IntegerLiteral:
+		return exp.Token.Pos.Offset
+	case *ast.FloatLiteral:
+		return exp.Token.Pos.Offset
+	case *ast.StringLiteral:
+		return exp.Token.Pos.Offset
+	case *ast.Boolean:
+		return exp.Token.Pos.Offset
+	case *ast.PrefixExpression:
+		return exp.Token.Pos.Offset
+	case *ast.IfExpression:
+		return exp.Token.Pos.Offset
+	case *ast.FunctionLiteral:
+		return exp.Token.Pos.Offset
+	case *ast.MacroLiteral:
+		return exp.Token.Pos.Offset
+	case *ast.ArrayLiteral:
+		return exp.Token.Pos.Offset
+	case *ast.HashLiteral:
+		return exp.Token.Pos.Offset
+	}
+	return 0
+}
+
+// "//"のコメントの行末の空白は取り除く
+func commentText(tok token.Token) string {
+	if strings.HasPrefix(tok.Literal, "//") {
+		return strings.TrimRight(tok.Literal, " \t\r")
+	}
+	return tok.Literal
+}
+
+// nextは同じ並びの次の文 (無い場合はnil)。式文の末尾の';'を省けるかの判断に使う
+func (p *printer) statement(stmt ast.Statement, next ast.Statement) {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		p.write("let ")
+		p.flush(stmt.Name.Token.Pos.Offset)
+		p.write(stmt.Name.Value)
+		p.flush(p.precedingToken(startOffset(stmt.Value)))
+		p.write(" = ")
+		p.expression(stmt.Value, lowest)
+		p.write(";")
+	case *ast.ReturnStatement:
+		p.write("return")
+		if stmt.ReturnValue != nil {
+			p.write(" ")
+			p.expression(stmt.ReturnValue, lowest)
+		}
+		p.write(";")
+	case *ast.ExpressionStatement:
+		p.expression(stmt.Expression, lowest)
+		// if式の後に続く式文は、if式への演算や呼び出しとして読まれないように';'で区切る
+		if _, ok := stmt.Expression.(*ast.IfExpression); !ok || isExpressionStatement(next) {
+			p.write(";")
+		}
+	case *ast.BlockStatement:
+		p.block(stmt)
+	case *ast.WhileStatement:
+		p.write("while (")
+		p.expression(stmt.Condition, lowest)
+		p.flush(p.precedingToken(stmt.Body.Token.Pos.Offset))
+		p.write(") ")
+		p.block(stmt.Body)
+	case *ast.ForStatement:
+		p.write("for (")
+		if stmt.Init != nil {
+			p.simpleStatement(stmt.Init)
+		}
+		p.write(";")
+		if stmt.Condition != nil {
+			p.write(" ")
+			p.expression(stmt.Condition, lowest)
+		}
+		p.write(";")
+		if stmt.Post != nil {
+			p.write(" ")
+			p.simpleStatement(stmt.Post)
+		}
+		p.flush(p.precedingToken(stmt.Body.Token.Pos.Offset))
+		p.write(") ")
+		p.block(stmt.Body)
+	case *ast.ForInStatement:
+		p.write("for (")
+		p.flush(stmt.Variable.Token.Pos.Offset)
+		p.write(stmt.Variable.Value)
+		p.flush(p.precedingToken(startOffset(stmt.Iterable)))
+		p.write(" in ")
+		p.expression(stmt.Iterable, lowest)
+		p.flush(p.precedingToken(stmt.Body.Token.Pos.Offset))
+		p.write(") ")
+		p.block(stmt.Body)
+	case *ast.BreakStatement:
+		p.write("break;")
+	case *ast.ContinueStatement:
+		p.write("continue;")
+	}
+}
+
+func isExpressionStatement(stmt ast.Statement) bool {
+	_, ok := stmt.(*ast.ExpressionStatement)
+	return ok
+}
+
+// for文の初期化と後処理を、末尾の';'を付けずに出力する
+func (p *printer) simpleStatement(stmt ast.Statement) {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		p.write("let ")
+		p.flush(stmt.Name.Token.Pos.Offset)
+		p.write(stmt.Name.Value)
+		p.flush(p.precedingToken(startOffset(stmt.Value)))
+		p.write(" = ")
+		p.expression(stmt.Value, lowest)
+	case *ast.ExpressionStatement:
+		p.expression(stmt.Expression, lowest)
+	}
+}
+
+func (p *printer) block(block *ast.BlockStatement) {
+	end, ok := p.closings[block.Token.Pos.Offset]
+	if !ok {
+		end = block.Token.Pos.Offset // ソースコードが無い場合
+	}
+	p.flush(block.Token.Pos.Offset)
+
+	if len(block.Statements) == 0 && !p.hasComments(block.Token.Pos.Offset, end) {
+		p.write("{}")
+		return
+	}
+
+	p.write("{\n")
+	p.indent++
+	p.statementList(block.Statements, end)
+	p.indent--
+	p.writeIndent()
+	p.write("}")
+}
+
+// startからendの間に未出力のコメントがあるかどうか
+func (p *printer) hasComments(start, end int) bool {
+	for i := p.tokenBefore(start) + 1; i < len(p.tokens) && p.tokens[i].Pos.Offset < end; i++ {
+		if p.tokens[i].Type == token.COMMENT && !p.printed[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// 式の結合の強さ。parserの優先順位と同じ順序で、括弧が必要かどうかの判断に使う
+const (
+	lowest = iota
+	assign
+	conditional
+	nullish
+	logicalOr
+	logicalAnd
+	equals
+	lessGreater
+	sum
+	product
+	prefix
+	postfix // 関数呼び出しと添字アクセス
+	atom    // リテラルや識別子など、括弧が不要な式
+)
+
+var infixPrecedences = map[string]int{
+	"??": nullish,
+	"||": logicalOr,
+	"&&": logicalAnd,
+	"==": equals,
+	"!=": equals,
+	"<":  lessGreater,
+	">":  lessGreater,
+	"<=": lessGreater,
+	">=": lessGreater,
+	"+":  sum,
+	"-":  sum,
+	"*":  product,
+	"/":  product,
+	"%":  product,
+}
+
+func precedence(exp ast.Expression) int {
+	switch exp := exp.(type) {
+	case *ast.InfixExpression:
+		return infixPrecedences[exp.Operator]
+	case *ast.AssignExpression:
+		return assign
+	case *ast.ConditionalExpression:
+		return conditional
+	case *ast.PrefixExpression:
+		return prefix
+	case *ast.CallExpression, *ast.IndexExpression:
+		return postfix
+	default:
+		return atom
+	}
+}
+
+// 式を出力する。式の結合がminより弱い場合は括弧で囲む
+func (p *printer) expression(exp ast.Expression, min int) {
+	p.flush(startOffset(exp))
+	if precedence(exp) < min {
+		p.write("(")
+		p.expression(exp, lowest)
+		p.write(")")
+		return
+	}
+
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		p.write(exp.Value)
+	case *ast.IntegerLiteral:
+		p.write(exp.Token.Literal)
+	case *ast.FloatLiteral:
+		p.write(exp.Token.Literal)
+	case *ast.StringLiteral:
+		p.write(exp.String())
+	case *ast.Boolean:
+		p.write(exp.String())
+	case *ast.PrefixExpression:
+		p.write(exp.Operator)
+		p.expression(exp.Right, prefix)
+	case *ast.InfixExpression:
+		// 左結合なので、同じ優先順位の式は右側のみ括弧で囲む
+		prec := infixPrecedences[exp.Operator]
+		p.expression(exp.Left, prec)
+		p.flush(exp.Token.Pos.Offset)
+		p.write(" " + exp.Operator + " ")
+		p.expression(exp.Right, prec+1)
+	case *ast.AssignExpression:
+		p.expression(exp.Target, postfix)
+		p.flush(exp.Token.Pos.Offset)
+		p.write(" " + exp.Operator + " ")
+		p.expression(exp.Value, lowest)
+	case *ast.ConditionalExpression:
+		p.expression(exp.Condition, conditional+1)
+		p.flush(exp.Token.Pos.Offset)
+		p.write(" ? ")
+		p.expression(exp.Consequence, lowest)
+		p.flush(p.precedingToken(startOffset(exp.Alternative)))
+		p.write(" : ")
+		p.expression(exp.Alternative, conditional)
+	case *ast.CallExpression:
+		p.expression(exp.Function, postfix)
+		p.expressionList("(", ")", exp.Token.Pos.Offset, exp.Arguments)
+	case *ast.IndexExpression:
+		p.expression(exp.Left, postfix)
+		p.flush(exp.Token.Pos.Offset)
+		p.write("[")
+		p.expression(exp.Index, lowest)
+		p.flush(p.closings[exp.Token.Pos.Offset])
+		p.write("]")
+	case *ast.ArrayLiteral:
+		p.expressionList("[", "]", exp.Token.Pos.Offset, exp.Elements)
+	case *ast.HashLiteral:
+		p.list("{", "}", exp.Token.Pos.Offset, len(exp.Pairs),
+			func(i int) int { return startOffset(exp.Pairs[i].Key) },
+			func(i int) {
+				p.expression(exp.Pairs[i].Key, lowest)
+				p.flush(p.precedingToken(startOffset(exp.Pairs[i].Value)))
+				p.write(": ")
+				p.expression(exp.Pairs[i].Value, lowest)
+			})
+	case *ast.IfExpression:
+		p.ifExpression(exp)
+	case *ast.FunctionLiteral:
+		p.write("fn")
+		p.parameters(p.followingToken(exp.Token.Pos.Offset), exp.Parameters)
+		p.block(exp.Body)
+	case *ast.MacroLiteral:
+		p.write("macro")
+		p.parameters(p.followingToken(exp.Token.Pos.Offset), exp.Parameters)
+		p.block(exp.Body)
+	}
+}
+
+// 括弧で囲んだ','区切りの並びを出力する。openOffsetは元のソースの開き括弧のオフセット
+// startはi番目の要素の開始位置、elemはi番目の要素を出力する
+// 元のソースで最初の要素が開き括弧の次の行にあるか、並びの中に"//"のコメントがある場合は、1行に1要素ずつ出力する
+func (p *printer) list(open, close string, openOffset, n int, start func(i int) int, elem func(i int)) {
+	closeOffset := p.closings[openOffset]
+	p.flush(openOffset)
+	p.write(open)
+
+	if p.tokens == nil || n == 0 || (p.lineOf(start(0)) == p.lineOf(openOffset) && !p.lineCommented[openOffset]) {
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				p.flush(p.precedingToken(start(i)))
+				p.write(", ")
+			}
+			elem(i)
+		}
+		p.flush(closeOffset)
+		p.write(close)
+		return
+	}
+
+	// 要素の後の同じ行のコメントは要素の行末に、それ以外のコメントは要素の間の行に出力する
+	// parserは末尾の','を受け付けないので、最後の要素の後には付けない
+	p.indent++
+	p.lineComments(start(0))
+	for i := 0; i < n; i++ {
+		p.ownLineComments(start(i))
+		p.write("\n")
+		p.writeIndent()
+		elem(i)
+		if i+1 < n {
+			p.flush(p.precedingToken(start(i + 1)))
+			p.write(",")
+			p.lineComments(start(i + 1))
+		}
+	}
+	p.lineComments(closeOffset)
+	p.ownLineComments(closeOffset)
+	p.indent--
+	p.write("\n")
+	p.writeIndent()
+	p.flush(closeOffset)
+	p.write(close)
+}
+
+func (p *printer) expressionList(open, close string, openOffset int, exps []ast.Expression) {
+	p.list(open, close, openOffset, len(exps),
+		func(i int) int { return startOffset(exps[i]) },
+		func(i int) { p.expression(exps[i], lowest) })
+}
+
+// openOffsetは元のソースの仮引数の'('のオフセット
+func (p *printer) parameters(openOffset int, params []*ast.Identifier) {
+	p.list("(", ")", openOffset, len(params),
+		func(i int) int { return params[i].Token.Pos.Offset },
+		func(i int) {
+			p.flush(params[i].Token.Pos.Offset)
+			p.write(params[i].Value)
+		})
+	p.write(" ")
+}
+
+func (p *printer) ifExpression(exp *ast.IfExpression) {
+	p.flush(exp.Token.Pos.Offset)
+	p.write("if (")
+	p.expression(exp.Condition, lowest)
+	p.flush(p.precedingToken(exp.Consequence.Token.Pos.Offset))
+	p.write(") ")
+	p.block(exp.Consequence)
+
+	if exp.Alternative == nil {
+		return
+	}
+	p.flush(p.precedingToken(exp.Alternative.Token.Pos.Offset))
+	p.write(" else ")
+	if elseIf, ok := elseIfExpression(exp.Alternative); ok {
+		p.ifExpression(elseIf)
+		return
+	}
+	p.block(exp.Alternative)
+}
+
+// parserがelse ifを表すために作る、if式だけを含むブロックであればそのif式を返す
+func elseIfExpression(block *ast.BlockStatement) (*ast.IfExpression, bool) {
+	if block.Token.Type != token.IF || len(block.Statements) != 1 {
+		return nil, false
+	}
+	stmt, ok := block.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		return nil, false
+	}
+	ifExpression, ok := stmt.Expression.(*ast.IfExpression)
+	return ifExpression, ok
+}
@@ -0,0 +1,467 @@
+package printer_test
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/mahiro72/monkey-lang/ast"
+	"github.com/mahiro72/monkey-lang/lexer"
+	"github.com/mahiro72/monkey-lang/parser"
+	"github.com/mahiro72/monkey-lang/printer"
+	testingHelper "github.com/mahiro72/monkey-lang/testing"
+	"github.com/mahiro72/monkey-lang/token"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	testingHelper.AssertEqual(t, []string{}, p.Errors())
+	return program
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "success: let文とreturn文",
+			input:    "let   x=1\nreturn x",
+			expected: "let x = 1;\nreturn x;\n",
+		},
+		{
+			name:     "success: 関数リテラルの本体を字下げする",
+			input:    `let add = fn(a,b){ let c = a+b; c }`,
+			expected: "let add = fn(a, b) {\n\tlet c = a + b;\n\tc;\n};\n",
+		},
+		{
+			name:     "success: 空のブロック",
+			input:    `fn(){}; while(x){}`,
+			expected: "fn() {};\nwhile (x) {}\n",
+		},
+		{
+			name:     "success: else if",
+			input:    `if(a){1}else if(b){2}else{3}`,
+			expected: "if (a) {\n\t1;\n} else if (b) {\n\t2;\n} else {\n\t3;\n}\n",
+		},
+		{
+			name:     "success: if式の後に式文が続く場合は';'で区切る",
+			input:    "if (a) { 1 };\n(b)",
+			expected: "if (a) {\n\t1;\n};\nb;\n",
+		},
+		{
+			name:     "success: 必要な括弧だけを残す",
+			input:    `((1 + 2)) * 3 - (4 - 5) + (-x) + -(a + b)`,
+			expected: "(1 + 2) * 3 - (4 - 5) + -x + -(a + b);\n",
+		},
+		{
+			name:     "success: 代入式と条件演算子",
+			input:    `x = (y += 1); a ? b : (c ? d : e); (a ? b : c) ? d : e`,
+			expected: "x = y += 1;\na ? b : c ? d : e;\n(a ? b : c) ? d : e;\n",
+		},
+		{
+			name:     "success: 呼び出しと添字アクセス",
+			input:    `(fn(x){x})(1)[0]; (a + b)[1]; f(1,[2,3],{"a":4})`,
+			expected: "fn(x) {\n\tx;\n}(1)[0];\n(a + b)[1];\nf(1, [2, 3], {\"a\": 4});\n",
+		},
+		{
+			name:     "success: ループ",
+			input:    `for(let i=0;i<3;i+=1){continue} for(;;){break;} for(x in xs){puts(x)}`,
+			expected: "for (let i = 0; i < 3; i += 1) {\n\tcontinue;\n}\nfor (;;) {\n\tbreak;\n}\nfor (x in xs) {\n\tputs(x);\n}\n",
+		},
+		{
+			name:     "success: 文字列はエスケープして出力する",
+			input:    `"a\tb\"c" + "\u{1F412}"`,
+			expected: "\"a\\tb\\\"c\" + \"🐒\";\n",
+		},
+		{
+			name:     "success: マクロリテラル",
+			input:    `let m = macro(a){quote(unquote(a))};`,
+			expected: "let m = macro(a) {\n\tquote(unquote(a));\n};\n",
+		},
+		{
+			name:     "success: 空行は1行にまとめて残す",
+			input:    "let a = 1;\n\n\n\nlet b = 2;\nlet c = 3;",
+			expected: "let a = 1;\n\nlet b = 2;\nlet c = 3;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := printer.Format("", []byte(tt.input))
+			testingHelper.AssertEqual(t, nil, err)
+			testingHelper.AssertEqual(t, tt.expected, string(out))
+		})
+	}
+}
+
+func TestFormatComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "success: 文の前のコメント",
+			input:    "// 先頭\nlet x = 1;\n/* ブロック\n   コメント */\nlet y = 2;",
+			expected: "// 先頭\nlet x = 1;\n/* ブロック\n   コメント */\nlet y = 2;\n",
+		},
+		{
+			name:     "success: 行末のコメント",
+			input:    "let x = 1;   // 一\nlet y = 2; /* 二 */",
+			expected: "let x = 1; // 一\nlet y = 2; /* 二 */\n",
+		},
+		{
+			name:     "success: ブロック内のコメント",
+			input:    "while (x) {\n// 前\nx -= 1 // 減らす\n\n   // 後\n}",
+			expected: "while (x) {\n\t// 前\n\tx -= 1; // 減らす\n\n\t// 後\n}\n",
+		},
+		{
+			name:     "success: コメントだけのブロック",
+			input:    "let f = fn() { /* 空 */ };",
+			expected: "let f = fn() {\n\t/* 空 */\n};\n",
+		},
+		{
+			name:     "success: 複数行にまたがる文の行末のコメント",
+			input:    "let f = fn() {\n  1\n}; // 末尾\nf()",
+			expected: "let f = fn() {\n\t1;\n}; // 末尾\nf();\n",
+		},
+		{
+			name:     "success: プログラム末尾のコメント",
+			input:    "let x = 1;\n\n// 終わり",
+			expected: "let x = 1;\n\n// 終わり\n",
+		},
+		{
+			name:     "success: elseの前後のコメント",
+			input:    "if (a) { 1 } /* c1 */ else /* c2 */ { 2 }",
+			expected: "if (a) {\n\t1;\n} /* c1 */ else /* c2 */ {\n\t2;\n}\n",
+		},
+		{
+			name:     "success: 仮引数の間のコメント",
+			input:    "fn(x /* p */, y) { x }",
+			expected: "fn(x /* p */, y) {\n\tx;\n};\n",
+		},
+		{
+			name:     "success: 式の途中のコメント",
+			input:    "let x = /* a */ 1 + /* b */ f(2 /* c */)[/* d */ 0];",
+			expected: "let x = /* a */ 1 + /* b */ f(2 /* c */)[/* d */ 0];\n",
+		},
+		{
+			name:     "success: 複数行のハッシュの要素ごとのコメント",
+			input:    "let h = {\n  \"a\": 1, // first\n  \"b\": 2 // second\n};",
+			expected: "let h = {\n\t\"a\": 1, // first\n\t\"b\": 2 // second\n};\n",
+		},
+		{
+			name:     "success: 複数行の関数呼び出しの引数ごとのコメント",
+			input:    "f(\n  1, // first\n  // second\n  [2, 3]\n)",
+			expected: "f(\n\t1, // first\n\t// second\n\t[2, 3]\n);\n",
+		},
+		{
+			name:     "success: 行コメントの後に続く式",
+			input:    "let x = 1 + // 一\n2; f(1, // 一\n2)",
+			expected: "let x = 1 + // 一\n\t2;\nf(\n\t1, // 一\n\t2\n);\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := printer.Format("", []byte(tt.input))
+			testingHelper.AssertEqual(t, nil, err)
+			testingHelper.AssertEqual(t, tt.expected, string(out))
+		})
+	}
+}
+
+func TestFormatError(t *testing.T) {
+	_, err := printer.Format("main.mk", []byte("let = 1;"))
+	testingHelper.AssertEqual(t, "main.mk:1:5: expected next token to be IDENT, got = instead", err.Error())
+}
+
+func TestFprint(t *testing.T) {
+	program := parse(t, "// コメント\nlet x = fn(a) { a * (1 + 2) }; x(3)")
+
+	var out bytes.Buffer
+	err := printer.Fprint(&out, program)
+	testingHelper.AssertEqual(t, nil, err)
+	testingHelper.AssertEqual(t, "let x = fn(a) {\n\ta * (1 + 2);\n};\nx(3);\n", out.String())
+}
+
+// 式文のトークンは式の先頭のトークンなので、冗長な括弧を取り除くと"("から変わる。構文木の構造には影響しないので比較しない
+var ignoreStatementToken = cmpopts.IgnoreFields(ast.ExpressionStatement{}, "Token")
+
+// 整形しても構文木は変わらず、整形済みのソースを再び整形しても変わらないことを確かめる
+func assertRoundTrip(t *testing.T, input string) {
+	t.Helper()
+	formatted, err := printer.Format("", []byte(input))
+	if err != nil {
+		t.Fatalf("format %q: %v", input, err)
+	}
+
+	expected := parse(t, input)
+	got := parse(t, string(formatted))
+	testingHelper.AssertEqual(t, expected, got, testingHelper.IgnorePosition, ignoreStatementToken)
+
+	again, err := printer.Format("", formatted)
+	if err != nil {
+		t.Fatalf("format %q: %v", formatted, err)
+	}
+	testingHelper.AssertEqual(t, string(formatted), string(again))
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	inputs := []string{
+		`let fib = fn(n) { if (n < 2) { return n; } fib(n - 1) + fib(n - 2) }; puts(fib(10));`,
+		`let map = fn(arr, f) { let iter = fn(arr, acc) { if (len(arr) == 0) { acc } else { iter(rest(arr), push(acc, f(first(arr)))) } }; iter(arr, []) };`,
+		`let unless = macro(cond, cons, alt) { quote(if (!(unquote(cond))) { unquote(cons) } else { unquote(alt) }) };`,
+		`let i = 0; while (i < 10) { i += 1; if (i % 2 == 0) { continue; } if (i > 7) { break; } }`,
+		`for (let i = 0; i < 3; i = i + 1) { for (x in "abc") { puts(i, x) } }`,
+		`let h = {"a": [1, 2.5, true], 1: {false: null ?? "x"}}; h["a"][0] *= -(1 - 2);`,
+		`a = b = c ? d : e ? f : g; (a = b) ? (c || d) && !e : f <= g >= h != i;`,
+		`if (x) { 1 } else if (y) { 2 } else if (z) { 3 }`,
+		`if (x) { 1 }; (fn() {})(); -(-1) - -1 + !!true`,
+		"// 先頭\nlet x = 1; // 行末\n\n/* 区切り */\nwhile (x) { // ループ\n  x -= 1\n  // 本体の後\n}\n// 末尾",
+		"let f = fn(a, // 一\n b) { a /* 二 */ + b }; if (f(1, 2) /* 三 */) { [\n  1, // 四\n  2\n] } else /* 五 */ if (x) { {1: /* 六 */ 2} }",
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			assertRoundTrip(t, input)
+		})
+	}
+}
+
+// 無作為に組み立てた構文木を出力し、構文解析した結果が元の木と同じ構造になることを確かめる
+func TestFprintRandomPrograms(t *testing.T) {
+	for seed := int64(0); seed < 500; seed++ {
+		g := &generator{rand: rand.New(rand.NewSource(seed))}
+		program := g.program()
+
+		var out bytes.Buffer
+		if err := printer.Fprint(&out, program); err != nil {
+			t.Fatal(err)
+		}
+		src := out.String()
+
+		t.Run(fmt.Sprint(seed), func(t *testing.T) {
+			parsed := parse(t, src)
+			// String()は全ての式を括弧で囲むので、演算子の結合が同じかどうかを比べられる
+			testingHelper.AssertEqual(t, program.String(), parsed.String())
+			assertRoundTrip(t, src)
+		})
+	}
+}
+
+// 構文解析に成功する入力は、整形しても同じ構文木になり、整形結果は冪等である
+func FuzzFormat(f *testing.F) {
+	seeds := []string{
+		`let x = if (true) {}; x + 1`,
+		`let add = fn(a, b) { a + b }; add(1)`,
+		`{"a": 1, true: [fn(x) { x }]}["a"]`,
+		`if (1 > 2) { 10 } else if (x) { return "s" + "t"; }`,
+		`a ? b : c ?? d || e && f; x[0] += 1`,
+		`while (x) { break } for (;;) { continue } for (x in y) {}`,
+		"// a\nlet x = 1; /* b */\n\n// c",
+		"f(\n1, // a\n/* b */ {2: // c\n3}\n) /* d */ + x[/* e */ 0]",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		p := parser.New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			return
+		}
+		assertRoundTrip(t, input)
+	})
+}
+
+// 構文的に正しい構文木を無作為に組み立てる
+type generator struct {
+	rand  *rand.Rand
+	depth int
+	loops int // 囲んでいるループの数 (関数リテラルの中では0に戻す)
+}
+
+func tok(typ token.TokenType, literal string) token.Token {
+	return token.Token{Type: typ, Literal: literal}
+}
+
+var (
+	identifiers     = []string{"a", "b", "x", "y", "fooBar", "名前"}
+	infixOperators  = []string{"+", "-", "*", "/", "%", "<", ">", "<=", ">=", "==", "!=", "&&", "||", "??"}
+	assignOperators = []string{"=", "+=", "-=", "*=", "/="}
+	stringValues    = []string{"", "hello", "a\"b", "tab\t", "改行\n", "\\", "🐒", "\x01"}
+)
+
+func (g *generator) program() *ast.Program {
+	return &ast.Program{Statements: g.statements(1 + g.rand.Intn(4))}
+}
+
+func (g *generator) statements(n int) []ast.Statement {
+	stmts := make([]ast.Statement, n)
+	for i := range stmts {
+		stmts[i] = g.statement()
+	}
+	return stmts
+}
+
+func (g *generator) block() *ast.BlockStatement {
+	return &ast.BlockStatement{Token: tok(token.LBRACE, "{"), Statements: g.statements(g.rand.Intn(3))}
+}
+
+func (g *generator) loopBody() *ast.BlockStatement {
+	g.loops++
+	defer func() { g.loops-- }()
+	return g.block()
+}
+
+func (g *generator) statement() ast.Statement {
+	g.depth++
+	defer func() { g.depth-- }()
+
+	n := 4
+	if g.depth < 4 {
+		n = 7
+	}
+	switch g.rand.Intn(n) {
+	case 0:
+		return &ast.LetStatement{Token: tok(token.LET, "let"), Name: g.identifier(), Value: g.expression()}
+	case 1:
+		return &ast.ReturnStatement{Token: tok(token.RETURN, "return"), ReturnValue: g.expression()}
+	case 2:
+		if g.loops > 0 {
+			if g.rand.Intn(2) == 0 {
+				return &ast.BreakStatement{Token: tok(token.BREAK, "break")}
+			}
+			return &ast.ContinueStatement{Token: tok(token.CONTINUE, "continue")}
+		}
+		fallthrough
+	case 3:
+		return &ast.ExpressionStatement{Expression: g.expression()}
+	case 4:
+		return &ast.WhileStatement{Token: tok(token.WHILE, "while"), Condition: g.expression(), Body: g.loopBody()}
+	case 5:
+		stmt := &ast.ForStatement{Token: tok(token.FOR, "for")}
+		if g.rand.Intn(2) == 0 {
+			stmt.Init = &ast.LetStatement{Token: tok(token.LET, "let"), Name: g.identifier(), Value: g.expression()}
+		}
+		if g.rand.Intn(2) == 0 {
+			stmt.Condition = g.expression()
+		}
+		if g.rand.Intn(2) == 0 {
+			stmt.Post = &ast.ExpressionStatement{Expression: g.assignExpression()}
+		}
+		stmt.Body = g.loopBody()
+		return stmt
+	default:
+		return &ast.ForInStatement{Token: tok(token.FOR, "for"), Variable: g.identifier(), Iterable: g.expression(), Body: g.loopBody()}
+	}
+}
+
+func (g *generator) identifier() *ast.Identifier {
+	name := identifiers[g.rand.Intn(len(identifiers))]
+	return &ast.Identifier{Token: tok(token.IDENT, name), Value: name}
+}
+
+func (g *generator) assignExpression() ast.Expression {
+	var target ast.Expression = g.identifier()
+	if g.rand.Intn(3) == 0 {
+		target = &ast.IndexExpression{Token: tok(token.LBRACKET, "["), Left: g.expression(), Index: g.expression()}
+	}
+	op := assignOperators[g.rand.Intn(len(assignOperators))]
+	return &ast.AssignExpression{Token: tok(token.ASSIGN, op), Target: target, Operator: op, Value: g.expression()}
+}
+
+func (g *generator) expression() ast.Expression {
+	g.depth++
+	defer func() { g.depth-- }()
+
+	if g.depth > 5 {
+		return g.atom()
+	}
+	switch g.rand.Intn(14) {
+	case 0, 1:
+		return g.atom()
+	case 2:
+		op := []string{"!", "-"}[g.rand.Intn(2)]
+		return &ast.PrefixExpression{Token: tok(token.MINUS, op), Operator: op, Right: g.expression()}
+	case 3, 4:
+		op := infixOperators[g.rand.Intn(len(infixOperators))]
+		return &ast.InfixExpression{Token: tok(token.PLUS, op), Left: g.expression(), Operator: op, Right: g.expression()}
+	case 5:
+		return g.assignExpression()
+	case 6:
+		return &ast.ConditionalExpression{Token: tok(token.QUESTION, "?"), Condition: g.expression(), Consequence: g.expression(), Alternative: g.expression()}
+	case 7:
+		exp := &ast.IfExpression{Token: tok(token.IF, "if"), Condition: g.expression(), Consequence: g.block()}
+		if g.rand.Intn(2) == 0 {
+			exp.Alternative = g.block()
+		}
+		return exp
+	case 8:
+		return &ast.CallExpression{Token: tok(token.LPAREN, "("), Function: g.expression(), Arguments: g.expressions()}
+	case 9:
+		return &ast.IndexExpression{Token: tok(token.LBRACKET, "["), Left: g.expression(), Index: g.expression()}
+	case 10:
+		return &ast.ArrayLiteral{Token: tok(token.LBRACKET, "["), Elements: g.expressions()}
+	case 11:
+		exp := &ast.HashLiteral{Token: tok(token.LBRACE, "{"), Pairs: []*ast.HashPair{}}
+		for i := g.rand.Intn(3); i > 0; i-- {
+			exp.Pairs = append(exp.Pairs, &ast.HashPair{Key: g.expression(), Value: g.expression()})
+		}
+		return exp
+	default:
+		// 関数の中ではbreakやcontinueを書けない
+		loops := g.loops
+		g.loops = 0
+		defer func() { g.loops = loops }()
+
+		params := []*ast.Identifier{}
+		for i := g.rand.Intn(3); i > 0; i-- {
+			params = append(params, g.identifier())
+		}
+		if g.rand.Intn(4) == 0 {
+			return &ast.MacroLiteral{Token: tok(token.MACRO, "macro"), Parameters: params, Body: g.block()}
+		}
+		return &ast.FunctionLiteral{Token: tok(token.FUNCTION, "fn"), Parameters: params, Body: g.block()}
+	}
+}
+
+func (g *generator) expressions() []ast.Expression {
+	exps := []ast.Expression{}
+	for i := g.rand.Intn(3); i > 0; i-- {
+		exps = append(exps, g.expression())
+	}
+	return exps
+}
+
+func (g *generator) atom() ast.Expression {
+	switch g.rand.Intn(5) {
+	case 0:
+		return g.identifier()
+	case 1:
+		n := g.rand.Int63n(1000)
+		return &ast.IntegerLiteral{Token: tok(token.INT, fmt.Sprint(n)), Value: n}
+	case 2:
+		n := float64(g.rand.Intn(1000)) / 8
+		literal := strings.TrimRight(fmt.Sprintf("%.3f", n), "0")
+		if strings.HasSuffix(literal, ".") {
+			literal += "0"
+		}
+		return &ast.FloatLiteral{Token: tok(token.FLOAT, literal), Value: n}
+	case 3:
+		s := stringValues[g.rand.Intn(len(stringValues))]
+		return &ast.StringLiteral{Token: tok(token.STRING, s), Value: s}
+	default:
+		value := g.rand.Intn(2) == 0
+		return &ast.Boolean{Token: tok(token.TRUE, fmt.Sprint(value)), Value: value}
+	}
+}
@@ -2,9 +2,11 @@ package repl
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/mahiro72/monkey-lang/evaluator"
 	"github.com/mahiro72/monkey-lang/lexer"
@@ -17,6 +19,8 @@ const PROMPT = ">>"
 func Start(in io.Reader, out io.Writer) {
 	scanner := bufio.NewScanner(in)
 	env := object.NewEnvironment()
+	macroEnv := object.NewEnvironment()
+	opts := evaluator.Options{Limits: evaluator.Limits{MaxDepth: evaluator.DefaultMaxDepth}, Output: out}
 
 	for {
 		fmt.Print(PROMPT)
@@ -34,11 +38,19 @@ func Start(in io.Reader, out io.Writer) {
 
 		program := p.ParseProgram()
 		if len(p.Errors()) != 0 {
-			printParserErrors(out, p.Errors())
+			printParserErrors(out, line, p.ParseErrors())
 			continue
 		}
 
-		evaluated := evaluator.Eval(program, env)
+		evaluator.DefineMacros(program, macroEnv)
+		expanded, err := evaluator.ExpandMacros(context.Background(), program, macroEnv, opts)
+		if err != nil {
+			io.WriteString(out, err.Inspect())
+			io.WriteString(out, "\n")
+			continue
+		}
+
+		evaluated := evaluator.EvalContext(context.Background(), expanded, env, opts)
 		if evaluated != nil {
 			io.WriteString(out, evaluated.Inspect())
 			io.WriteString(out, "\n")
@@ -60,11 +72,14 @@ const MONKEY_FACE = `
            '-----'
 `
 
-func printParserErrors(out io.Writer, errors []string) {
+func printParserErrors(out io.Writer, source string, errors parser.ErrorList) {
 	io.WriteString(out, MONKEY_FACE)
 	io.WriteString(out, "Woops! We ran into some monkey business here!\n")
 	io.WriteString(out, " parser errors:\n")
-	for _, msg := range errors {
-		io.WriteString(out, "\t"+msg+"\n")
+	for _, e := range errors {
+		io.WriteString(out, "\t"+e.Error()+"\n")
+		if caret := e.Caret(source); caret != "" {
+			io.WriteString(out, "\t"+strings.ReplaceAll(caret, "\n", "\n\t")+"\n")
+		}
 	}
 }
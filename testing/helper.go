@@ -4,11 +4,16 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/mahiro72/monkey-lang/token"
 )
 
-func AssertEqual(t *testing.T, want, got any) {
+// トークンの位置情報を比較対象から除外するオプション
+var IgnorePosition = cmpopts.IgnoreFields(token.Token{}, "Pos", "End")
+
+func AssertEqual(t *testing.T, want, got any, opts ...cmp.Option) {
 	t.Helper()
-	if diff := cmp.Diff(want, got); diff != "" {
+	if diff := cmp.Diff(want, got, opts...); diff != "" {
 		t.Fatalf(diff)
 	}
 }
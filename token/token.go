@@ -1,12 +1,17 @@
 package token
 
+import "fmt"
+
 const (
 	ILLEGAL = "ILLEGAL" //トークンや文字が未知
 	EOF     = "EOF"     // ファイル終端 (end of file)
+	COMMENT = "COMMENT" // コメント (字句解析器で有効にした場合のみ)
 
 	// 識別子 + リテラル
-	IDENT = "IDENT" // add, foobar, x, y...
-	INT   = "INT"   // 1,2,3...
+	IDENT  = "IDENT"  // add, foobar, x, y...
+	INT    = "INT"    // 1,2,3...
+	FLOAT  = "FLOAT"  // 1.5, 1e9, 2.5e-3...
+	STRING = "STRING" // "foo", "bar"...
 
 	// 演算子
 	ASSIGN   = "="
@@ -15,21 +20,38 @@ const (
 	BANG     = "!"
 	ASTERISK = "*"
 	SLASH    = "/"
+	PERCENT  = "%"
+
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
 
-	LT = "<"
-	GT = ">"
+	LT    = "<"
+	GT    = ">"
+	LT_EQ = "<="
+	GT_EQ = ">="
 
 	EQ     = "=="
 	NOT_EQ = "!="
 
+	AND = "&&"
+	OR  = "||"
+
+	QUESTION = "?"
+	NULLISH  = "??"
+
 	// デリミタ
 	COMMA     = ","
 	SEMICOLON = ";"
+	COLON     = ":"
 
-	LPAREN = "("
-	RPAREN = ")"
-	LBRACE = "{"
-	RBRACE = "}"
+	LPAREN   = "("
+	RPAREN   = ")"
+	LBRACE   = "{"
+	RBRACE   = "}"
+	LBRACKET = "["
+	RBRACKET = "]"
 
 	// キーワード
 	FUNCTION = "FUNCTION"
@@ -39,23 +61,63 @@ const (
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	WHILE    = "WHILE"
+	FOR      = "FOR"
+	IN       = "IN"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+	MACRO    = "MACRO"
 )
 
 type TokenType string
 
 type Token struct {
-	Type    TokenType //トークンのタイプの識別
-	Literal string    //
+	Type    TokenType `json:"type"`    //トークンのタイプの識別
+	Literal string    `json:"literal"` //
+	Pos     Position  `json:"pos"`     // トークンの開始位置
+	End     Position  `json:"end"`     // トークン直後の位置
+}
+
+// ソースコード上の位置
+type Position struct {
+	Filename string `json:"filename,omitempty"` // ファイル名 (無い場合は空文字)
+	Offset   int    `json:"offset"`             // 先頭からのバイトオフセット (0始まり)
+	Line     int    `json:"line"`               // 行番号 (1始まり)
+	Column   int    `json:"column"`             // 列番号 (1始まり、バイトではなく文字単位)
+}
+
+// 行番号が設定されていれば有効な位置とみなす
+func (p Position) IsValid() bool { return p.Line > 0 }
+
+// file:line:column 形式で位置を返す (ファイル名が無い場合は line:column)
+func (p Position) String() string {
+	s := p.Filename
+	if p.IsValid() {
+		if s != "" {
+			s += ":"
+		}
+		s += fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	if s == "" {
+		s = "-"
+	}
+	return s
 }
 
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    LET,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
+	"fn":       FUNCTION,
+	"let":      LET,
+	"true":     TRUE,
+	"false":    FALSE,
+	"if":       IF,
+	"else":     ELSE,
+	"return":   RETURN,
+	"while":    WHILE,
+	"for":      FOR,
+	"in":       IN,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"macro":    MACRO,
 }
 
 func LookupIdent(ident string) TokenType {